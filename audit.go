@@ -0,0 +1,206 @@
+package ecdsa_tools
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+)
+
+// Checks of curve parameters against known attacks, in addition to Validate.
+// See https://safecurves.cr.yp.to and SEC1 (https://www.secg.org/sec1-v2.pdf) section 3.1.1.2.1.
+//
+// - MOV / Frey-Ruck: the Weil or Tate pairing maps the group into the multiplicative group of F_p^k
+//   where k (the embedding degree) is the smallest integer with p^k = 1 (mod n), if k is small
+//   the discrete log can be solved there with subexponential index calculus methods
+// - Anomalous curves (#E = p): Smart's attack solves the discrete log in linear time via p-adic lifting
+// - Twist security: an implementation that does not check points are on the curve (eg x-only ladders)
+//   can be fed points on the quadratic twist (order 2p + 2 - #E), leaking the privkey modulo each of its
+//   small factors, so the largest prime factor of the twist order bounds the security
+
+const (
+	maxEmbeddingDegree = 100     // Per SEC1
+	minSecurityBits    = 100     // Per SafeCurves
+	twistRhoBudget     = 1 << 18 // Pollard rho iterations when factoring the twist order
+)
+
+type CurveAudit struct {
+	EmbeddingDegree int  // Smallest k with p^k = 1 (mod n), 0 if larger than maxEmbeddingDegree
+	Anomalous       bool // #E = p
+	RhoSecurity     int  // Bits of work for Pollard rho on the curve
+
+	TwistOrder    *big.Int
+	TwistFactors  []*big.Int // Prime factors found (with multiplicity) in increasing order
+	TwistCofactor *big.Int   // Remaining unfactored composite, nil if fully factored
+	TwistSecurity int        // Bits of work for Pollard rho on the largest prime factor, a lower bound if TwistCofactor is set
+}
+
+// Requires the cofactor H, see Validate for the basic parameter checks
+func (c *Curve) Audit() (*CurveAudit, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if c.H == nil {
+		return nil, errors.New("cofactor required")
+	}
+
+	points := new(big.Int).Mul(c.H, c.N)
+
+	audit := &CurveAudit{
+		EmbeddingDegree: embeddingDegree(c.P, c.N, maxEmbeddingDegree),
+		Anomalous:       points.Cmp(c.P) == 0,
+		RhoSecurity:     rhoSecurity(c.N),
+	}
+
+	// #E + #E' = 2p + 2
+	audit.TwistOrder = new(big.Int).Add(c.P, big.NewInt(1))
+	audit.TwistOrder.Lsh(audit.TwistOrder, 1)
+	audit.TwistOrder.Sub(audit.TwistOrder, points)
+
+	audit.TwistFactors, audit.TwistCofactor = factor(audit.TwistOrder, twistRhoBudget)
+	if len(audit.TwistFactors) > 0 {
+		audit.TwistSecurity = rhoSecurity(audit.TwistFactors[len(audit.TwistFactors)-1])
+	}
+	// An unsplit composite has a prime factor of at least half its bit length
+	if audit.TwistCofactor != nil {
+		audit.TwistSecurity = max(audit.TwistSecurity, rhoSecurity(new(big.Int).Sqrt(audit.TwistCofactor)))
+	}
+
+	return audit, nil
+}
+
+// Human readable descriptions of any weaknesses found, empty if none
+func (a *CurveAudit) Issues() []string {
+	var rv []string
+
+	if a.EmbeddingDegree != 0 {
+		rv = append(rv, fmt.Sprintf("embedding degree %d <= %d (MOV attack)", a.EmbeddingDegree, maxEmbeddingDegree))
+	}
+	if a.Anomalous {
+		rv = append(rv, "anomalous curve #E = p (Smart's attack)")
+	}
+	if a.RhoSecurity < minSecurityBits {
+		rv = append(rv, fmt.Sprintf("rho security %d bits < %d", a.RhoSecurity, minSecurityBits))
+	}
+	// Undetermined (rather than weak) when a composite is left unsplit
+	if a.TwistCofactor == nil && a.TwistSecurity < minSecurityBits {
+		rv = append(rv, fmt.Sprintf("twist security %d bits < %d", a.TwistSecurity, minSecurityBits))
+	}
+
+	return rv
+}
+
+// Smallest k in [1, maxK] with p^k = 1 (mod n), 0 if none
+func embeddingDegree(p, n *big.Int, maxK int) int {
+	pk := new(big.Int).Mod(p, n)
+	q := new(big.Int).Set(pk)
+
+	for k := 1; k <= maxK; k++ {
+		if pk.Cmp(big.NewInt(1)) == 0 {
+			return k
+		}
+		pk.Mul(pk, q).Mod(pk, n)
+	}
+	return 0
+}
+
+// log2(sqrt(pi * l / 4)), the expected number of group operations for Pollard rho in a group of prime order l
+func rhoSecurity(l *big.Int) int {
+	f, _ := new(big.Float).SetInt(l).Float64()
+	return int(math.Log2(math.Sqrt(math.Pi * f / 4)))
+}
+
+// Factors n by trial division then Pollard rho (with the given iteration budget per composite).
+// Returns the prime factors found in increasing order and the product of any composites left unsplit (or nil).
+func factor(n *big.Int, budget int) ([]*big.Int, *big.Int) {
+	var primes []*big.Int
+	n = new(big.Int).Set(n)
+
+	for d := int64(2); d < 1<<16; d++ {
+		bd := big.NewInt(d)
+		for new(big.Int).Mod(n, bd).Sign() == 0 {
+			primes = append(primes, bd)
+			n.Div(n, bd)
+		}
+		if n.Cmp(big.NewInt(1)) == 0 {
+			return primes, nil
+		}
+		if new(big.Int).Mul(bd, bd).Cmp(n) > 0 {
+			return append(primes, n), nil
+		}
+	}
+
+	var rest *big.Int
+	todo := []*big.Int{n}
+	for len(todo) > 0 {
+		m := todo[len(todo)-1]
+		todo = todo[:len(todo)-1]
+
+		if m.ProbablyPrime(20) {
+			primes = append(primes, m)
+		} else if d := pollardRho(m, budget); d != nil {
+			todo = append(todo, d, new(big.Int).Div(m, d))
+		} else if rest == nil {
+			rest = m
+		} else {
+			rest.Mul(rest, m)
+		}
+	}
+
+	slices.SortFunc(primes, func(a, b *big.Int) int { return a.Cmp(b) })
+	return primes, rest
+}
+
+// Brent's variant of Pollard's rho, returns a non-trivial factor of the odd composite n or nil if none found
+func pollardRho(n *big.Int, budget int) *big.Int {
+	one := big.NewInt(1)
+
+	for c := int64(1); c <= 3; c++ {
+		f := func(x *big.Int) *big.Int {
+			x.Mul(x, x)
+			x.Add(x, big.NewInt(c))
+			return x.Mod(x, n)
+		}
+
+		y, x, ys := big.NewInt(2), new(big.Int), new(big.Int)
+		q, g := big.NewInt(1), big.NewInt(1)
+		diff := new(big.Int)
+
+		// Products of 128 differences share a single gcd
+		const m = 128
+		iterations := 0
+		for r := 1; g.Cmp(one) == 0 && iterations < budget; r <<= 1 {
+			x.Set(y)
+			for i := 0; i < r; i++ {
+				f(y)
+			}
+
+			for k := 0; k < r && g.Cmp(one) == 0; k += m {
+				ys.Set(y)
+				for i := 0; i < m && i < r-k; i++ {
+					f(y)
+					q.Mul(q, diff.Sub(x, y).Abs(diff)).Mod(q, n)
+				}
+				g.GCD(nil, nil, q, n)
+			}
+			iterations += 2 * r
+		}
+
+		// The product overshot, backtrack one step at a time
+		if g.Cmp(n) == 0 {
+			for {
+				f(ys)
+				if g.GCD(nil, nil, diff.Sub(x, ys).Abs(diff), n).Cmp(one) != 0 {
+					break
+				}
+			}
+		}
+
+		if g.Cmp(one) != 0 && g.Cmp(n) != 0 {
+			return g
+		}
+	}
+
+	return nil
+}
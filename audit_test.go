@@ -0,0 +1,137 @@
+package ecdsa_tools
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	// https://safecurves.cr.yp.to/twist.html
+	table := map[string]int{
+		"secp256k1":  109,
+		"prime256v1": 120,
+	}
+
+	for name, twistSecurity := range table {
+		audit, err := curves[name].Audit()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if audit.TwistCofactor != nil || audit.TwistSecurity != twistSecurity {
+			t.Errorf("%s: twist security %d (unfactored %v)", name, audit.TwistSecurity, audit.TwistCofactor)
+		}
+
+		product := big.NewInt(1)
+		for _, f := range audit.TwistFactors {
+			product.Mul(product, f)
+		}
+		if product.Cmp(audit.TwistOrder) != 0 {
+			t.Errorf("%s: twist factors product mismatch", name)
+		}
+
+		if issues := audit.Issues(); len(issues) != 0 {
+			t.Errorf("%s: %s", name, strings.Join(issues, ", "))
+		}
+	}
+}
+
+func TestAuditCurves(t *testing.T) {
+	for _, curve := range Curves() {
+		audit, err := curve.Audit()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if issues := audit.Issues(); len(issues) != 0 {
+			t.Errorf("%s: %s", curve.Name, strings.Join(issues, ", "))
+		}
+	}
+}
+
+func TestAuditWeakCurves(t *testing.T) {
+	// Supersingular y^2 = x^3 + x with p = 3 (mod 4) has p + 1 points and embedding degree 2
+	supersingular := &Curve{
+		P: big.NewInt(4051), A: big.NewInt(1), B: big.NewInt(0),
+		Gx: big.NewInt(2922), Gy: big.NewInt(2468), N: big.NewInt(1013), H: big.NewInt(4),
+	}
+	audit, err := supersingular.Audit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if audit.EmbeddingDegree != 2 || audit.Anomalous {
+		t.Errorf("supersingular: embedding degree %d", audit.EmbeddingDegree)
+	}
+
+	// The twist of a supersingular curve has the same order
+	if audit.TwistOrder.Int64() != 4052 {
+		t.Errorf("supersingular: twist order %s", audit.TwistOrder)
+	}
+
+	// Found by exhaustive search for #E = p
+	anomalous := &Curve{
+		P: big.NewInt(2003), A: big.NewInt(4), B: big.NewInt(9),
+		Gx: big.NewInt(1165), Gy: big.NewInt(1214), N: big.NewInt(2003), H: big.NewInt(1),
+	}
+	audit, err = anomalous.Audit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !audit.Anomalous || audit.EmbeddingDegree != 0 {
+		t.Error("anomalous: not detected")
+	}
+
+	issues := strings.Join(audit.Issues(), ", ")
+	for _, s := range []string{"anomalous", "rho security", "twist security"} {
+		if !strings.Contains(issues, s) {
+			t.Errorf("anomalous: missing %q issue", s)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	curve := curves["secp256k1"]
+
+	// Only a cofactor of one is within the Hasse bound
+	wrongCofactor := *curve
+	wrongCofactor.H = big.NewInt(2)
+	if err := wrongCofactor.Validate(); err == nil || !strings.Contains(err.Error(), "hasse") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// The cofactor is derived when registering
+	toy := &Curve{
+		Name: "toy",
+		P:    big.NewInt(4051), A: big.NewInt(1), B: big.NewInt(0),
+		Gx: big.NewInt(2922), Gy: big.NewInt(2468), N: big.NewInt(1013),
+	}
+	if err := RegisterCurve(toy); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		curvesMutex.Lock()
+		delete(curves, "toy")
+		curvesMutex.Unlock()
+	})
+	if toy.H.Int64() != 4 || toy.BitSize != 12 {
+		t.Errorf("toy: cofactor %s, bit size %d", toy.H, toy.BitSize)
+	}
+}
+
+func TestFactor(t *testing.T) {
+	// (2^31 - 1) * (2^61 - 1) * 3^2
+	n := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 31), big.NewInt(1))
+	m := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 61), big.NewInt(1))
+	product := new(big.Int).Mul(n, m)
+	product.Mul(product, big.NewInt(9))
+
+	primes, rest := factor(product, 1<<20)
+	if rest != nil || len(primes) != 4 || primes[0].Int64() != 3 || primes[1].Int64() != 3 ||
+		primes[2].Cmp(n) != 0 || primes[3].Cmp(m) != 0 {
+		t.Errorf("factor: %v, %v", primes, rest)
+	}
+
+	if primes, rest := factor(big.NewInt(97), 1); len(primes) != 1 || rest != nil {
+		t.Errorf("factor prime: %v, %v", primes, rest)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"golang.org/x/crypto/ripemd160" // nolint
 
 	"crypto/sha256"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"math/big"
@@ -85,32 +84,7 @@ func main() {
 			}
 		}
 
-		// Uncompressed public keys are prefixed with 04 followed by the x and y coordinates
-
-		compressedPubkey := func(pubkey *ecdsa.PubKey) []byte {
-			x := pubkey.E.X.Text(16)
-			if len(x) > 64 {
-				panic(errors.New("pubkey x coord too long"))
-			}
-			x = fmt.Sprintf("%064s", x)
-
-			z := big.NewInt(1)
-			z.And(z, pubkey.E.Y)
-			yIsOdd := z.Cmp(big.NewInt(1)) == 0
-
-			var s string
-			if yIsOdd {
-				s = fmt.Sprintf("03%s", x)
-			} else {
-				s = fmt.Sprintf("02%s", x)
-			}
-
-			b, err := hex.DecodeString(s)
-			if err != nil {
-				panic(err)
-			}
-			return b
-		}
+		// Compressed public keys are prefixed with 02 (even y) or 03 (odd y) followed by the x coordinate
 
 		sha256Sum := func(data []byte) []byte {
 			sum := sha256.Sum256(data)
@@ -124,7 +98,7 @@ func main() {
 			return sum[:]
 		}
 
-		hash := ripemd160Sum(sha256Sum(compressedPubkey(pubkey)))
+		hash := ripemd160Sum(sha256Sum(pubkey.E.MarshalCompressed()))
 
 		// Prepend network id byte (0x00 mainnet, 0x6f testnet)
 		hash = append([]byte{0x00}, hash...)
@@ -0,0 +1,126 @@
+package main
+
+import (
+	ecdsa "github.com/jo-makar/ecdsa-tools"
+	"github.com/jo-makar/ecdsa-tools/dlog"
+
+	"context"
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+func main() {
+	minBits := flag.Int("min-bits", 16, "smallest curve size in bits")
+	maxBits := flag.Int("max-bits", 40, "largest curve size in bits")
+	step := flag.Int("step", 4, "curve size increment in bits")
+	workers := flag.Int("workers", 0, "pollard rho workers (0 for one per cpu)")
+	timeout := flag.Duration("timeout", time.Minute, "time limit per solver")
+	flag.Parse()
+
+	fmt.Printf("%-5s %-14s %-12s %s\n", "bits", "method", "time", "result")
+
+	for bits := *minBits; bits <= *maxBits; bits += *step {
+		// Prime order curves for the generic methods, any order for Pohlig-Hellman
+		prime, err := randomCurve(bits, true)
+		if err != nil {
+			panic(err)
+		}
+		smooth, err := randomCurve(bits, false)
+		if err != nil {
+			panic(err)
+		}
+
+		solvers := []struct {
+			method string
+			curve  *ecdsa.Curve
+			solve  func(ctx context.Context, p, q *ecdsa.Point) (*big.Int, error)
+		}{
+			{"bsgs", prime, func(_ context.Context, p, q *ecdsa.Point) (*big.Int, error) {
+				return dlog.BSGS(p, q, nil)
+			}},
+			{"pollard-rho", prime, func(ctx context.Context, p, q *ecdsa.Point) (*big.Int, error) {
+				return dlog.PollardRho(ctx, p, q, nil, *workers)
+			}},
+			{"pohlig-hellman", smooth, func(ctx context.Context, p, q *ecdsa.Point) (*big.Int, error) {
+				return dlog.PohligHellman(ctx, p, q, nil, *workers)
+			}},
+		}
+
+		for _, solver := range solvers {
+			k, g, q, err := randomKey(solver.curve)
+			if err != nil {
+				panic(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+			start := time.Now()
+			actual, err := solver.solve(ctx, g, q)
+			elapsed := time.Since(start)
+			cancel()
+
+			result := "ok"
+			if err != nil {
+				result = err.Error()
+			} else if actual.Cmp(k) != 0 {
+				result = fmt.Sprintf("wrong key %s != %s", actual, k)
+			}
+			fmt.Printf("%-5d %-14s %-12s %s (n = %s)\n", bits, solver.method, elapsed.Round(time.Microsecond),
+				result, solver.curve.N)
+		}
+	}
+}
+
+// Random curve over a prime field of the given size, of prime order if requested
+func randomCurve(bits int, primeOrder bool) (*ecdsa.Curve, error) {
+	for {
+		p, err := rand.Prime(rand.Reader, bits)
+		if err != nil {
+			return nil, err
+		}
+		a, err := rand.Int(rand.Reader, p)
+		if err != nil {
+			return nil, err
+		}
+		b, err := rand.Int(rand.Reader, p)
+		if err != nil {
+			return nil, err
+		}
+		curve := &ecdsa.Curve{P: p, A: a, B: b}
+
+		// A generator from a random x coordinate, decompression fails when x^3 + ax + b is not a square
+		var g *ecdsa.Point
+		for g == nil {
+			x, err := rand.Int(rand.Reader, p)
+			if err != nil {
+				return nil, err
+			}
+			enc := make([]byte, 1+(bits+7)/8)
+			enc[0] = 0x02
+			x.FillBytes(enc[1:])
+			g, _ = ecdsa.UnmarshalPoint(curve, enc)
+		}
+		curve.Gx, curve.Gy = g.X, g.Y
+
+		// Singular curves and the like are simply skipped
+		isPrime, err := curve.ComputeOrder()
+		if err != nil || (primeOrder && !isPrime) {
+			continue
+		}
+		return curve, nil
+	}
+}
+
+// Random k in [1, n) and q = k * G
+func randomKey(curve *ecdsa.Curve) (*big.Int, *ecdsa.Point, *ecdsa.Point, error) {
+	k, err := rand.Int(rand.Reader, new(big.Int).Sub(curve.N, big.NewInt(1)))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	k.Add(k, big.NewInt(1))
+
+	g := &ecdsa.Point{X: curve.Gx, Y: curve.Gy, Curve: curve}
+	return k, g, g.Multiply(k), nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	ecdsa "github.com/jo-makar/ecdsa-tools"
+	"github.com/jo-makar/ecdsa-tools/lattice"
+
+	"crypto/rand"
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Signs with nonces leaking a few bits (as a side channel or a poor random number generator might) and
+// recovers the privkey with the lattice attack, using increasing numbers of signatures until it succeeds.
+// RFC 6979 (SignDeterministic) nonces are uniform and never reused so give nothing to work with.
+
+func main() {
+	curve := flag.String("curve", "secp256k1", "curve name")
+	bits := flag.Int("bits", 16, "nonce bits leaked per signature")
+	leak := flag.String("leak", "msb", "leak kind: msb, lsb or biased (top bits zero)")
+	maxSigs := flag.Int("max-sigs", 100, "largest number of signatures to try")
+	flag.Parse()
+
+	kind := lattice.LeakMSB
+	switch *leak {
+	case "msb", "biased":
+	case "lsb":
+		kind = lattice.LeakLSB
+	default:
+		panic(fmt.Errorf("unknown leak kind: %s", *leak))
+	}
+
+	privkey, err := ecdsa.NewRandomPrivKeyViaOpenSSL(*curve)
+	if err != nil {
+		panic(err)
+	}
+	pubkey := privkey.CalcPubKey()
+	n := privkey.Curve.N
+
+	hashFunc := func(data []byte) []byte {
+		rv := sha256.Sum256(data)
+		return rv[:]
+	}
+
+	// Weakened nonce, returning the bits leaked
+	nonce := func() (*big.Int, *big.Int) {
+		max := n
+		if *leak == "biased" {
+			max = new(big.Int).Lsh(big.NewInt(1), uint(n.BitLen()-*bits))
+		}
+		for {
+			k, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				panic(err)
+			}
+			if k.Sign() == 0 {
+				continue
+			}
+
+			if kind == lattice.LeakMSB {
+				return k, new(big.Int).Rsh(k, uint(n.BitLen()-*bits))
+			}
+			return k, new(big.Int).Mod(k, new(big.Int).Lsh(big.NewInt(1), uint(*bits)))
+		}
+	}
+
+	fmt.Printf("%s, %d bit %s leak per signature\n", *curve, *bits, *leak)
+	fmt.Printf("%-5s %-12s %s\n", "sigs", "time", "result")
+
+	var sigs []lattice.LeakedSignature
+	for m := 1; m <= *maxSigs; m++ {
+		var value *big.Int
+		msg := []byte(fmt.Sprintf("message %d", m))
+		sig, err := privkey.SignWithNonce(msg, hashFunc, func() *big.Int {
+			var k *big.Int
+			k, value = nonce()
+			return k
+		})
+		if err != nil {
+			panic(err)
+		}
+		sigs = append(sigs, lattice.LeakedSignature{
+			R: sig.R, S: sig.S, Hash: hashFunc(msg), Kind: kind, Bits: *bits, Value: value,
+		})
+
+		// Information theoretically at least n.BitLen() bits are required in total
+		if m*(*bits) <= n.BitLen() {
+			continue
+		}
+
+		start := time.Now()
+		recovered, err := lattice.RecoverKey(pubkey, sigs)
+		elapsed := time.Since(start).Round(time.Millisecond)
+		if err != nil {
+			fmt.Printf("%-5d %-12s %s\n", m, elapsed, err)
+			continue
+		}
+
+		fmt.Printf("%-5d %-12s recovered privkey %x\n", m, elapsed, recovered.D)
+		if recovered.D.Cmp(privkey.D) != 0 {
+			panic("recovered privkey mismatch")
+		}
+		return
+	}
+
+	fmt.Printf("privkey not recovered\n")
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	ecdsa "github.com/jo-makar/ecdsa-tools"
+
+	"bufio"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// Reads signatures, one per line, as the hex encoded message hash followed by either the hex encoded DER signature
+// or the hex encoded r and s values (whitespace separated). Blank lines and those starting with # are ignored.
+//
+//   nonce-reuse -pubkey pubkey.pem signatures.txt
+//
+// Exits with status 1 if any nonce reuse is found, the recovered privkey is written with -out.
+
+func main() {
+	pubKeyPath := flag.String("pubkey", "pubkey.pem", "signer pubkey (pem or der)")
+	outPath := flag.String("out", "", "write the recovered privkey (pem) to this file")
+	flag.Parse()
+
+	pubkey, err := ecdsa.NewPubKeyViaOpenSSLFile(*pubKeyPath)
+	if err != nil {
+		panic(err)
+	}
+
+	var input io.Reader = os.Stdin
+	if flag.NArg() > 0 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			panic(err)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	var sigs []ecdsa.SignedHash
+	var lines []int
+	scanner := bufio.NewScanner(input)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		sig, err := parseLine(line, pubkey.Curve)
+		if err != nil {
+			panic(fmt.Errorf("line %d: %w", lineNum, err))
+		}
+		sigs = append(sigs, sig)
+		lines = append(lines, lineNum)
+	}
+	if err := scanner.Err(); err != nil {
+		panic(err)
+	}
+
+	// Pairs that could not be recovered do not prevent reporting the others
+	reuses, err := pubkey.RecoverNonceReuse(sigs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	fmt.Printf("%d signatures, %d reused nonces\n", len(sigs), len(reuses))
+	if len(reuses) == 0 {
+		return
+	}
+
+	for _, reuse := range reuses {
+		fmt.Printf("lines %d and %d share r = %x\n", lines[reuse.First], lines[reuse.Second], sigs[reuse.First].R)
+		fmt.Printf("  k = %x\n", reuse.K)
+		fmt.Printf("  d = %x\n", reuse.PrivKey.D)
+	}
+
+	if *outPath != "" {
+		data, err := reuses[0].PrivKey.MarshalSEC1PEM()
+		if err != nil {
+			panic(err)
+		}
+		if err := os.WriteFile(*outPath, data, 0600); err != nil {
+			panic(err)
+		}
+	}
+
+	os.Exit(1)
+}
+
+func parseLine(line string, curve *ecdsa.Curve) (ecdsa.SignedHash, error) {
+	var sig ecdsa.SignedHash
+
+	fields := strings.Fields(line)
+	if len(fields) != 2 && len(fields) != 3 {
+		return sig, fmt.Errorf("expected 2 or 3 fields, got %d", len(fields))
+	}
+
+	var err error
+	if sig.Hash, err = hex.DecodeString(fields[0]); err != nil {
+		return sig, fmt.Errorf("hash: %w", err)
+	}
+
+	if len(fields) == 2 {
+		der, err := hex.DecodeString(fields[1])
+		if err != nil {
+			return sig, fmt.Errorf("signature: %w", err)
+		}
+		signature, err := ecdsa.UnmarshalSignatureDER(curve, der)
+		if err != nil {
+			return sig, err
+		}
+		sig.R, sig.S = signature.R, signature.S
+		return sig, nil
+	}
+
+	for i, v := range []**big.Int{&sig.R, &sig.S} {
+		var ok bool
+		if *v, ok = new(big.Int).SetString(strings.TrimPrefix(fields[1+i], "0x"), 16); !ok {
+			return sig, fmt.Errorf("invalid %c value", "rs"[i])
+		}
+	}
+	return sig, nil
+}
@@ -5,10 +5,8 @@ import (
 
 	"bytes"
 	"crypto/sha256"
-	"encoding/asn1"
 	"errors"
 	"fmt"
-	"math/big"
 	"os"
 	"os/exec"
 	"strings"
@@ -46,7 +44,7 @@ func main() {
 	// Signature generation
 	//
 
-	var r, s *big.Int
+	var signature *ecdsa.Signature
 	{
 		bytes, err := os.ReadFile(FILE_PATH)
 		if err != nil {
@@ -57,15 +55,17 @@ func main() {
 			rv := sha256.Sum256(data)
 			return rv[:]
 		}
-		r, s = privkey.Sign(bytes, hashFunc)
+		signature, err = privkey.SignMessage(bytes, hashFunc)
+		if err != nil {
+			panic(err)
+		}
 	}
 
 	//
-	// Signature ASN.1 marshalling
+	// Signature DER marshalling
 	//
 
-	signature := []*big.Int{r, s}
-	encodedSignature, err := asn1.Marshal(signature)
+	encodedSignature, err := signature.MarshalDER()
 	if err != nil {
 		panic(err)
 	}
@@ -5,10 +5,8 @@ import (
 
 	"bytes"
 	"crypto/sha256"
-	"encoding/asn1"
 	"errors"
 	"fmt"
-	"math/big"
 	"os"
 	"os/exec"
 )
@@ -67,18 +65,11 @@ func main() {
 	// ... | openssl asn1parse -inform der
 	//
 
-	var signature []*big.Int
-	if rest, err := asn1.Unmarshal(encodedSignature, &signature); err != nil {
+	signature, err := ecdsa.UnmarshalSignatureDER(pubkey.Curve, encodedSignature)
+	if err != nil {
 		panic(err)
-	} else if len(rest) != 0 {
-		panic(errors.New("trailing bytes"))
-	}
-	if len(signature) != 2 {
-		panic(errors.New("unexpected sequence length"))
 	}
 
-	r, s := signature[0], signature[1]
-
 	//
 	// Signature verification
 	//
@@ -93,7 +84,7 @@ func main() {
 		return rv[:]
 	}
 
-	if pubkey.Verify(r, s, data, hashFunc) {
+	if pubkey.Verify(signature.R, signature.S, data, hashFunc) {
 		fmt.Printf("valid signature\n")
 	} else {
 		panic(errors.New("invalid signature"))
@@ -0,0 +1,236 @@
+package ecdsa_tools
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// Constant-time scalar multiplication for use with secret scalars (privkeys and nonces).
+//
+// Points are kept in homogeneous projective coordinates (X, Y, Z) representing the affine point (X/Z, Y/Z)
+// with (0, 1, 0) the point at infinity. The complete addition formulas of Renes, Costello and Batina
+// (https://eprint.iacr.org/2015/1060, algorithm 1) have no exceptional cases (doubling and the point at infinity
+// included) for curves of odd order, so no branches depend on the operands.
+
+type ctCurve struct {
+	f     *field
+	a, b3 []uint64 // a and 3 * b in the Montgomery domain
+	n     *field   // Scalar arithmetic modulo the order (nil when unknown)
+
+	generatorOnce  sync.Once
+	generatorTable [][]*projectivePoint // See MultiplyGenerator
+}
+
+type projectivePoint struct {
+	X, Y, Z []uint64
+}
+
+var ctCurves sync.Map // Registered *Curve -> *ctCurve
+
+// Only registered curves are cached (bounding the cache by the registry), others are set up on each call
+func ctCurveFor(curve *Curve) (*ctCurve, error) {
+	if c, ok := ctCurves.Load(curve); ok {
+		return c.(*ctCurve), nil
+	}
+
+	f, err := newField(curve.P)
+	if err != nil {
+		return nil, fmt.Errorf("p: %w", err)
+	}
+	c := &ctCurve{
+		f:  f,
+		a:  f.toMontgomery(curve.A),
+		b3: f.toMontgomery(new(big.Int).Mul(curve.B, big.NewInt(3))),
+	}
+	if curve.N != nil {
+		c.n, _ = newField(curve.N) // Left nil for an even (or unusable) order
+	}
+
+	if !curve.registered() {
+		return c, nil
+	}
+	actual, _ := ctCurves.LoadOrStore(curve, c)
+	return actual.(*ctCurve), nil
+}
+
+func (c *ctCurve) newPoint() *projectivePoint {
+	return &projectivePoint{X: c.f.element(), Y: c.f.element(), Z: c.f.element()}
+}
+
+func (c *ctCurve) infinity() *projectivePoint {
+	q := c.newPoint()
+	copy(q.Y, c.f.one)
+	return q
+}
+
+func (c *ctCurve) fromAffine(p *Point) *projectivePoint {
+	if p.AtInf {
+		return c.infinity()
+	}
+
+	q := c.newPoint()
+	copy(q.X, c.f.toMontgomery(p.X))
+	copy(q.Y, c.f.toMontgomery(p.Y))
+	copy(q.Z, c.f.one)
+	return q
+}
+
+func (c *ctCurve) toAffine(p *projectivePoint, curve *Curve) *Point {
+	f := c.f
+
+	// Only whether the result is the point at infinity is revealed
+	if f.isZero(p.Z) == 1 {
+		return &Point{AtInf: true, Curve: curve}
+	}
+
+	zInv := f.element()
+	f.inv(zInv, p.Z)
+
+	x, y := f.element(), f.element()
+	f.mul(x, p.X, zInv)
+	f.mul(y, p.Y, zInv)
+
+	return &Point{X: f.fromMontgomery(x), Y: f.fromMontgomery(y), Curve: curve}
+}
+
+// r = p + q, r may alias p or q
+func (c *ctCurve) add(r, p, q *projectivePoint) {
+	f := c.f
+
+	var buf [9][maxLimbs]uint64
+	t0, t1, t2 := buf[0][:f.limbs], buf[1][:f.limbs], buf[2][:f.limbs]
+	t3, t4, t5 := buf[3][:f.limbs], buf[4][:f.limbs], buf[5][:f.limbs]
+	x3, y3, z3 := buf[6][:f.limbs], buf[7][:f.limbs], buf[8][:f.limbs]
+
+	f.mul(t0, p.X, q.X)
+	f.mul(t1, p.Y, q.Y)
+	f.mul(t2, p.Z, q.Z)
+	f.add(t3, p.X, p.Y)
+	f.add(t4, q.X, q.Y)
+	f.mul(t3, t3, t4)
+	f.add(t4, t0, t1)
+	f.sub(t3, t3, t4)
+	f.add(t4, p.X, p.Z)
+	f.add(t5, q.X, q.Z)
+	f.mul(t4, t4, t5)
+	f.add(t5, t0, t2)
+	f.sub(t4, t4, t5)
+	f.add(t5, p.Y, p.Z)
+	f.add(x3, q.Y, q.Z)
+	f.mul(t5, t5, x3)
+	f.add(x3, t1, t2)
+	f.sub(t5, t5, x3)
+	f.mul(z3, c.a, t4)
+	f.mul(x3, c.b3, t2)
+	f.add(z3, x3, z3)
+	f.sub(x3, t1, z3)
+	f.add(z3, t1, z3)
+	f.mul(y3, x3, z3)
+	f.add(t1, t0, t0)
+	f.add(t1, t1, t0)
+	f.mul(t2, c.a, t2)
+	f.mul(t4, c.b3, t4)
+	f.add(t1, t1, t2)
+	f.sub(t2, t0, t2)
+	f.mul(t2, c.a, t2)
+	f.add(t4, t4, t2)
+	f.mul(t0, t1, t4)
+	f.add(y3, y3, t0)
+	f.mul(t0, t5, t4)
+	f.mul(x3, t3, x3)
+	f.sub(x3, x3, t0)
+	f.mul(t0, t3, t1)
+	f.mul(z3, t5, z3)
+	f.add(z3, z3, t0)
+
+	copy(r.X, x3)
+	copy(r.Y, y3)
+	copy(r.Z, z3)
+}
+
+// Swaps p and q if cond == 1
+func (c *ctCurve) swap(p, q *projectivePoint, cond uint64) {
+	ctSwap(p.X, q.X, cond)
+	ctSwap(p.Y, q.Y, cond)
+	ctSwap(p.Z, q.Z, cond)
+}
+
+// Montgomery ladder over a fixed number of scalar bits, k must be in the range [0, 2^bitLen)
+func (c *ctCurve) ladder(p *projectivePoint, k []uint64, bitLen int) *projectivePoint {
+	r0, r1 := c.infinity(), c.newPoint()
+	copy(r1.X, p.X)
+	copy(r1.Y, p.Y)
+	copy(r1.Z, p.Z)
+
+	// Invariant r1 - r0 = p, the swaps keep the sequence of operations independent of the bits
+	var swap uint64
+	for i := bitLen - 1; i >= 0; i-- {
+		bit := (k[i/64] >> (i % 64)) & 1
+		c.swap(r0, r1, swap^bit)
+		swap = bit
+
+		c.add(r1, r0, r1)
+		c.add(r0, r0, r0)
+	}
+	c.swap(r0, r1, swap)
+
+	return r0
+}
+
+// Scalar multiplication in constant time with respect to k, for use whenever k is secret.
+// The curve must have odd order (eg a prime order curve such as those in the registry).
+func (p *Point) MultiplyConstantTime(k *big.Int) *Point {
+	return mustPoint(p.TryMultiplyConstantTime(k))
+}
+
+func (p *Point) TryMultiplyConstantTime(k *big.Int) (*Point, error) {
+	if p.AtInf {
+		return &Point{AtInf: true, Curve: p.Curve}, nil
+	}
+
+	c, err := ctCurveFor(p.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	// Process a fixed number of bits, the scalar is reduced by the group order (h * n) first when known.
+	// Points need not lie in the subgroup generated by G so k cannot be reduced by n alone when h != 1.
+	var order *big.Int
+	if p.Curve.N != nil && p.Curve.H != nil {
+		order = p.Curve.N
+		if p.Curve.H.Cmp(big.NewInt(1)) != 0 {
+			order = new(big.Int).Mul(p.Curve.H, p.Curve.N)
+		}
+	}
+
+	var bitLen int
+	if order != nil {
+		if k.Sign() == -1 || k.Cmp(order) >= 0 {
+			k = new(big.Int).Mod(k, order)
+		}
+		bitLen = order.BitLen()
+	} else {
+		if k.Sign() == -1 {
+			q, err := p.TryNegate()
+			if err != nil {
+				return nil, err
+			}
+			return q.TryMultiplyConstantTime(new(big.Int).Neg(k))
+		}
+		bitLen = p.Curve.P.BitLen() + 1
+		if k.BitLen() > bitLen {
+			return nil, errors.New("scalar too large")
+		}
+	}
+
+	limbs := (bitLen + 63) / 64
+	q := c.toAffine(c.ladder(c.fromAffine(p), toLimbs(k, limbs), bitLen), p.Curve)
+
+	if !q.AtInf && !q.OnCurve() {
+		return nil, fmt.Errorf("multiplied %w", ErrNotOnCurve)
+	}
+
+	return q, nil
+}
@@ -0,0 +1,92 @@
+package ecdsa_tools
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestMultiplyConstantTime(t *testing.T) {
+	for name, curve := range curves {
+		g := &Point{X: curve.Gx, Y: curve.Gy, Curve: curve}
+
+		scalars := []*big.Int{
+			big.NewInt(1),
+			big.NewInt(2),
+			big.NewInt(3),
+			new(big.Int).Sub(curve.N, big.NewInt(1)),
+			new(big.Int).Add(curve.N, big.NewInt(5)),
+			big.NewInt(-7),
+		}
+		for i := 0; i < 8; i++ {
+			k, err := rand.Int(rand.Reader, curve.N)
+			if err != nil {
+				t.Fatal(err)
+			}
+			scalars = append(scalars, k)
+		}
+
+		for _, k := range scalars {
+			expected := g.Multiply(new(big.Int).Mod(k, curve.N))
+			if !g.MultiplyConstantTime(k).Equals(expected) {
+				t.Errorf("%s: k * g mismatch for k = %x", name, k)
+			}
+
+			// Also for a point other than the generator
+			q := expected.Double()
+			if !q.MultiplyConstantTime(k).Equals(q.Multiply(new(big.Int).Mod(k, curve.N))) {
+				t.Errorf("%s: k * q mismatch for k = %x", name, k)
+			}
+		}
+
+		// n * g = O
+		if !g.MultiplyConstantTime(curve.N).AtInf {
+			t.Errorf("%s: n * g != o", name)
+		}
+	}
+}
+
+func TestMultiplyConstantTimeCofactor(t *testing.T) {
+	// #E = 9987 = 3 * 3329
+	n := big.NewInt(3329)
+	curve := &Curve{
+		Name: "cofactor", P: big.NewInt(10007), A: big.NewInt(1), B: big.NewInt(9),
+		N: n, H: big.NewInt(3),
+	}
+
+	// A point of order 3n, outside the subgroup of order n
+	var p *Point
+	for x := int64(0); p == nil; x++ {
+		y, ok := modSqrt(curve.rhs(new(big.Int), big.NewInt(x)), curve.P)
+		if !ok {
+			continue
+		}
+		q := &Point{X: big.NewInt(x), Y: y, Curve: curve}
+		if !q.Multiply(n).AtInf && !q.Multiply(big.NewInt(3)).AtInf {
+			p = q
+		}
+	}
+	g := p.Multiply(big.NewInt(3))
+	curve.Gx, curve.Gy = g.X, g.Y
+
+	for _, k := range []*big.Int{
+		new(big.Int).Add(n, big.NewInt(1)),
+		new(big.Int).Mul(n, big.NewInt(2)),
+		new(big.Int).Add(new(big.Int).Mul(n, big.NewInt(3)), big.NewInt(2)),
+		big.NewInt(-5),
+	} {
+		if !p.MultiplyConstantTime(k).Equals(p.Multiply(k)) {
+			t.Errorf("k * p mismatch for k = %s", k)
+		}
+	}
+}
+
+func BenchmarkMultiplyConstantTime(b *testing.B) {
+	curve := curves["secp256k1"]
+	g := &Point{X: curve.Gx, Y: curve.Gy, Curve: curve}
+	k := new(big.Int).Sub(curve.N, big.NewInt(2))
+
+	for i := 0; i < b.N; i++ {
+		g.MultiplyConstantTime(k)
+	}
+}
@@ -0,0 +1,263 @@
+package ecdsa_tools
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Point counting: #E(F_p), the number of points on the curve (including the point at infinity).
+//
+// By Hasse's theorem #E = p + 1 - t with |t| <= 2 * sqrt(p), t being the trace of Frobenius.
+// - Naive: #E = 1 + sum over x of (1 + legendre(x^3 + ax + b)), O(p)
+// - Baby-step giant-step (Mestre): find the order of random points within the Hasse interval, O(p^(1/4))
+// - Schoof: compute t modulo small primes l via the action of Frobenius on the l-torsion (see schoof.go),
+//   polynomial in log(p)
+
+const (
+	naiveMaxBits = 16 // CountPoints uses the naive method up to this size of p
+	bsgsMaxBits  = 64 // And baby-step giant-step up to this size, Schoof beyond
+)
+
+func (c *Curve) CountPoints() (*big.Int, error) {
+	switch bits := c.P.BitLen(); {
+	case bits <= naiveMaxBits:
+		return c.CountPointsNaive()
+	case bits <= bsgsMaxBits:
+		return c.CountPointsBSGS()
+	default:
+		return c.CountPointsSchoof()
+	}
+}
+
+// Fills in N (the order of G), H and BitSize from the number of points on the curve.
+// Returns whether the number of points is prime (ie H = 1 and N prime).
+// Registered curves are shared and refused, see CalcOrder to compute the values without modifying c.
+func (c *Curve) ComputeOrder() (bool, error) {
+	if c.registered() {
+		return false, fmt.Errorf("%s: curve registered", c.Name)
+	}
+
+	n, h, err := c.CalcOrder()
+	if err != nil {
+		return false, err
+	}
+
+	c.N, c.H, c.BitSize = n, h, c.P.BitLen()
+	return h.Cmp(big.NewInt(1)) == 0 && n.ProbablyPrime(20), nil
+}
+
+// Computes n (the order of G) and the cofactor h from the number of points on the curve, without modifying c
+func (c *Curve) CalcOrder() (*big.Int, *big.Int, error) {
+	if c.Gx == nil || c.Gy == nil {
+		return nil, nil, errors.New("missing generator point")
+	}
+
+	points, err := c.CountPoints()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Lagrange: the order of G divides the number of points
+	primes, rest := factor(points, 1<<24)
+	if rest != nil {
+		return nil, nil, errors.New("unable to factor the number of points")
+	}
+
+	if !(&Point{X: c.Gx, Y: c.Gy, Curve: c}).OnCurve() {
+		return nil, nil, errors.New("g not on curve")
+	}
+	g := newJacobianPoint(&Point{X: c.Gx, Y: c.Gy, Curve: c})
+
+	// Remove each prime factor from the order while the generator remains annihilated
+	n := new(big.Int).Set(points)
+	for _, q := range primes {
+		m := new(big.Int).Div(n, q)
+		if g.multiply(m).atInf() {
+			n = m
+		}
+	}
+
+	return n, new(big.Int).Div(points, n), nil
+}
+
+func (c *Curve) CountPointsNaive() (*big.Int, error) {
+	if err := c.checkCountable(); err != nil {
+		return nil, err
+	}
+	if !c.P.IsInt64() {
+		return nil, errors.New("p too large")
+	}
+
+	// The point at infinity, then two points for each x with a non-zero square rhs and one when zero
+	count := int64(1)
+	x, rhs := new(big.Int), new(big.Int)
+	for ; x.Cmp(c.P) < 0; x.Add(x, big.NewInt(1)) {
+		c.rhs(rhs, x)
+		count += int64(1 + big.Jacobi(rhs, c.P))
+	}
+
+	return big.NewInt(count), nil
+}
+
+func (c *Curve) CountPointsBSGS() (*big.Int, error) {
+	if err := c.checkCountable(); err != nil {
+		return nil, err
+	}
+	if c.P.Cmp(big.NewInt(229)) <= 0 {
+		// Mestre's theorem only guarantees that the order is determined by the curve or its twist for p > 229
+		return c.CountPointsNaive()
+	}
+
+	// Quadratic twist y^2 = x^3 + a * d^2 * x + b * d^3 for a non-residue d, with #E + #E' = 2p + 2
+	d := big.NewInt(2)
+	for big.Jacobi(d, c.P) != -1 {
+		d.Add(d, big.NewInt(1))
+	}
+	d2 := new(big.Int).Mul(d, d)
+	twist := &Curve{
+		P: c.P,
+		A: new(big.Int).Mod(new(big.Int).Mul(c.A, d2), c.P),
+		B: new(big.Int).Mod(new(big.Int).Mul(c.B, d2.Mul(d2, d)), c.P),
+	}
+
+	// Hasse interval [p + 1 - 2 * sqrt(p), p + 1 + 2 * sqrt(p)], the same for the curve and the twist
+	sqrt4P := new(big.Int).Sqrt(new(big.Int).Lsh(c.P, 2))
+	lo := new(big.Int).Add(c.P, big.NewInt(1))
+	hi := new(big.Int).Add(lo, sqrt4P)
+	lo.Sub(lo, sqrt4P)
+
+	curveLCM, twistLCM := big.NewInt(1), big.NewInt(1)
+
+	for attempt := 0; attempt < 64; attempt++ {
+		curve, lcm := c, curveLCM
+		if attempt%2 == 1 {
+			curve, lcm = twist, twistLCM
+		}
+
+		p, err := curve.randomPoint()
+		if err != nil {
+			return nil, err
+		}
+
+		order, err := bsgsOrder(p, lo, hi)
+		if err != nil {
+			return nil, err
+		}
+
+		gcd := new(big.Int).GCD(nil, nil, lcm, order)
+		lcm.Mul(lcm, order.Div(order, gcd))
+
+		// The order is determined once only a single multiple of the lcm lies in the interval
+		first := new(big.Int).Add(lo, lcm)
+		first.Sub(first, big.NewInt(1))
+		first.Div(first, lcm).Mul(first, lcm)
+		if new(big.Int).Add(first, lcm).Cmp(hi) <= 0 {
+			continue
+		}
+
+		if curve == twist {
+			first.Sub(new(big.Int).Lsh(new(big.Int).Add(c.P, big.NewInt(1)), 1), first)
+		}
+		return first, nil
+	}
+
+	return nil, errors.New("point count not determined")
+}
+
+// Order of the point p given a multiple of it lies in [lo, hi]
+func bsgsOrder(p *jacobianPoint, lo, hi *big.Int) (*big.Int, error) {
+	// Baby steps j * P for j in [0, s), keyed by x (j * P and -j * P share it)
+	width := new(big.Int).Sub(hi, lo)
+	s := new(big.Int).Sqrt(width).Int64() + 1
+
+	baby := make(map[string]int64, s)
+	babyPoints := make([]*Point, s)
+	q := newJacobianInf(p.Curve)
+	for j := int64(0); j < s; j++ {
+		babyPoints[j] = q.toAffine()
+		if !q.atInf() {
+			baby[string(babyPoints[j].X.Bytes())] = j
+		}
+		q = q.add(p)
+	}
+
+	// Giant steps R = (lo + i * s) * P, a match R = +-j * P gives (lo + i * s -+ j) * P = O
+	var multiple *big.Int
+	step := q
+	r := p.multiply(lo)
+	for i := int64(0); i <= s && multiple == nil; i++ {
+		base := new(big.Int).Add(lo, new(big.Int).Mul(big.NewInt(i), big.NewInt(s)))
+
+		if r.atInf() {
+			multiple = base
+		} else {
+			a := r.toAffine()
+			if j, ok := baby[string(a.X.Bytes())]; ok {
+				if a.Y.Cmp(babyPoints[j].Y) == 0 {
+					multiple = base.Sub(base, big.NewInt(j))
+				} else {
+					multiple = base.Add(base, big.NewInt(j))
+				}
+			}
+		}
+
+		r = r.add(step)
+	}
+	if multiple == nil {
+		return nil, errors.New("no multiple of the point order in the hasse interval")
+	}
+
+	primes, rest := factor(multiple, 1<<24)
+	if rest != nil {
+		return nil, errors.New("unable to factor the point order multiple")
+	}
+
+	order := multiple
+	for _, q := range primes {
+		m := new(big.Int).Div(order, q)
+		if p.multiply(m).atInf() {
+			order = m
+		}
+	}
+	return order, nil
+}
+
+func (c *Curve) randomPoint() (*jacobianPoint, error) {
+	rhs := new(big.Int)
+	for {
+		x, err := rand.Int(rand.Reader, c.P)
+		if err != nil {
+			return nil, err
+		}
+
+		if y, ok := modSqrt(c.rhs(rhs, x), c.P); ok {
+			return newJacobianPoint(&Point{X: x, Y: y, Curve: c}), nil
+		}
+	}
+}
+
+// Sets v = x^3 + ax + b (mod p)
+func (c *Curve) rhs(v, x *big.Int) *big.Int {
+	v.Mul(x, x)
+	v.Add(v, c.A)
+	v.Mul(v, x)
+	v.Add(v, c.B)
+	return v.Mod(v, c.P)
+}
+
+func (c *Curve) checkCountable() error {
+	if c.P == nil || c.A == nil || c.B == nil {
+		return errors.New("missing curve parameters")
+	}
+	if c.P.Cmp(big.NewInt(3)) <= 0 || !c.P.ProbablyPrime(20) {
+		return errors.New("p not a prime > 3")
+	}
+
+	if c.singular() {
+		return errors.New("singular curve")
+	}
+
+	return nil
+}
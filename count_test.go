@@ -0,0 +1,120 @@
+package ecdsa_tools
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// Random non-singular curve over a random prime field of the given size
+func randomCurve(r *rand.Rand, bits int) *Curve {
+	for {
+		p := new(big.Int).Rand(r, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+		p.SetBit(p, bits-1, 1)
+		if !p.ProbablyPrime(20) {
+			continue
+		}
+
+		c := &Curve{P: p, A: new(big.Int).Rand(r, p), B: new(big.Int).Rand(r, p)}
+		if c.checkCountable() == nil {
+			return c
+		}
+	}
+}
+
+func TestCountPointsBSGS(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for _, bits := range []int{9, 12, 16} {
+		for i := 0; i < 16; i++ {
+			curve := randomCurve(r, bits)
+
+			expected, err := curve.CountPointsNaive()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !hasseBound(curve.P, expected) {
+				t.Errorf("p = %s: %s outside the hasse bound", curve.P, expected)
+			}
+
+			if actual, err := curve.CountPointsBSGS(); err != nil {
+				t.Fatal(err)
+			} else if actual.Cmp(expected) != 0 {
+				t.Errorf("p = %s, a = %s, b = %s: %s != %s", curve.P, curve.A, curve.B, actual, expected)
+			}
+		}
+	}
+}
+
+func TestCountPointsNaive(t *testing.T) {
+	// y^2 = x^3 + x over p = 3 (mod 4) is supersingular with p + 1 points
+	for _, p := range []int64{7, 11, 19, 23, 4051} {
+		curve := &Curve{P: big.NewInt(p), A: big.NewInt(1), B: big.NewInt(0)}
+		if points, err := curve.CountPoints(); err != nil || points.Int64() != p+1 {
+			t.Errorf("p = %d: %v, %v", p, points, err)
+		}
+	}
+
+	singular := &Curve{P: big.NewInt(11), A: big.NewInt(0), B: big.NewInt(0)}
+	if _, err := singular.CountPoints(); err == nil {
+		t.Error("singular curve counted")
+	}
+}
+
+func TestComputeOrder(t *testing.T) {
+	supersingular := &Curve{
+		P: big.NewInt(4051), A: big.NewInt(1), B: big.NewInt(0),
+		Gx: big.NewInt(2922), Gy: big.NewInt(2468),
+	}
+	n, h, err := supersingular.CalcOrder()
+	if err != nil {
+		t.Fatalf("supersingular: %v", err)
+	}
+	if n.Int64() != 1013 || h.Int64() != 4 {
+		t.Errorf("supersingular: n = %s, h = %s", n, h)
+	}
+	if supersingular.N != nil || supersingular.H != nil || supersingular.BitSize != 0 {
+		t.Error("supersingular: curve modified")
+	}
+
+	anomalous := &Curve{
+		P: big.NewInt(2003), A: big.NewInt(4), B: big.NewInt(9),
+		Gx: big.NewInt(1165), Gy: big.NewInt(1214),
+	}
+	if prime, err := anomalous.ComputeOrder(); err != nil || !prime {
+		t.Fatalf("anomalous: %v, %v", prime, err)
+	}
+	if anomalous.N.Int64() != 2003 || anomalous.H.Int64() != 1 || anomalous.BitSize != 11 {
+		t.Errorf("anomalous: n = %s, h = %s", anomalous.N, anomalous.H)
+	}
+	if err := anomalous.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	// A larger curve with a random generator
+	r := rand.New(rand.NewSource(2))
+	curve := randomCurve(r, 48)
+	g, err := curve.randomPoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ga := g.toAffine()
+	curve.Gx, curve.Gy = ga.X, ga.Y
+
+	if _, err := curve.ComputeOrder(); err != nil {
+		t.Fatal(err)
+	}
+	points, err := curve.CountPoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if new(big.Int).Mul(curve.N, curve.H).Cmp(points) != 0 {
+		t.Error("n * h != #E")
+	}
+	if !newJacobianPoint(ga).multiply(curve.N).atInf() {
+		t.Error("n * g != o")
+	}
+	if _, err := curves["secp256k1"].ComputeOrder(); err == nil {
+		t.Error("registered curve modified")
+	}
+}
@@ -1,14 +1,26 @@
 package ecdsa_tools
 
 import (
+	"encoding/asn1"
 	"errors"
+	"fmt"
 	"math/big"
+	"slices"
+	"strings"
+	"sync"
 )
 
 type Curve struct {
+	Name    string
+	OID     asn1.ObjectIdentifier // Named curve identifier (RFC 5480), nil if none
+	BitSize int                   // Size of the underlying field
+
 	P, A, B *big.Int // Elliptic curve definition: (y^2) % p = (x^3 + ax + b) % p
 	Gx, Gy  *big.Int // Generator point (a point on the curve above)
-	N       *big.Int // Number of possible points on the curve
+	N       *big.Int // Order of the generator point
+	H       *big.Int // Cofactor: number of points on the curve / n
+
+	glv *glvParams // Efficient endomorphism if available (nil otherwise)
 }
 
 func (c *Curve) Equals(d *Curve) bool {
@@ -29,31 +41,285 @@ func newBigInt(s string) *big.Int {
 	return i
 }
 
+// Registered curves by name, see RegisterCurve
 var curves = map[string]*Curve{
+	"brainpoolP256r1": {
+		// https://neuromancer.sk/std/brainpool/brainpoolP256r1
+		Name:    "brainpoolP256r1",
+		OID:     asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 7},
+		BitSize: 256,
+
+		P:  newBigInt("0xa9fb57dba1eea9bc3e660a909d838d726e3bf623d52620282013481d1f6e5377"),
+		A:  newBigInt("0x7d5a0975fc2c3057eef67530417affe7fb8055c126dc5c6ce94a4b44f330b5d9"),
+		B:  newBigInt("0x26dc5c6ce94a4b44f330b5d9bbd77cbf958416295cf7e1ce6bccdc18ff8c07b6"),
+		Gx: newBigInt("0x8bd2aeb9cb7e57cb2c4b482ffc81b7afb9de27e1e3bd23c23a4453bd9ace3262"),
+		Gy: newBigInt("0x547ef835c3dac4fd97f8461a14611dc9c27745132ded8e545c1d54c72f046997"),
+		N:  newBigInt("0xa9fb57dba1eea9bc3e660a909d838d718c397aa3b561a6f7901e0e82974856a7"),
+		H:  big.NewInt(1),
+	},
+	"brainpoolP384r1": {
+		// https://neuromancer.sk/std/brainpool/brainpoolP384r1
+		Name:    "brainpoolP384r1",
+		OID:     asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 11},
+		BitSize: 384,
+
+		P:  newBigInt("0x8cb91e82a3386d280f5d6f7e50e641df152f7109ed5456b412b1da197fb71123acd3a729901d1a71874700133107ec53"),
+		A:  newBigInt("0x7bc382c63d8c150c3c72080ace05afa0c2bea28e4fb22787139165efba91f90f8aa5814a503ad4eb04a8c7dd22ce2826"),
+		B:  newBigInt("0x04a8c7dd22ce28268b39b55416f0447c2fb77de107dcd2a62e880ea53eeb62d57cb4390295dbc9943ab78696fa504c11"),
+		Gx: newBigInt("0x1d1c64f068cf45ffa2a63a81b7c13f6b8847a3e77ef14fe3db7fcafe0cbd10e8e826e03436d646aaef87b2e247d4af1e"),
+		Gy: newBigInt("0x8abe1d7520f9c2a45cb1eb8e95cfd55262b70b29feec5864e19c054ff99129280e4646217791811142820341263c5315"),
+		N:  newBigInt("0x8cb91e82a3386d280f5d6f7e50e641df152f7109ed5456b31f166e6cac0425a7cf3ab6af6b7fc3103b883202e9046565"),
+		H:  big.NewInt(1),
+	},
+	"brainpoolP512r1": {
+		// https://neuromancer.sk/std/brainpool/brainpoolP512r1
+		Name:    "brainpoolP512r1",
+		OID:     asn1.ObjectIdentifier{1, 3, 36, 3, 3, 2, 8, 1, 1, 13},
+		BitSize: 512,
+
+		P:  newBigInt("0xaadd9db8dbe9c48b3fd4e6ae33c9fc07cb308db3b3c9d20ed6639cca703308717d4d9b009bc66842aecda12ae6a380e62881ff2f2d82c68528aa6056583a48f3"),
+		A:  newBigInt("0x7830a3318b603b89e2327145ac234cc594cbdd8d3df91610a83441caea9863bc2ded5d5aa8253aa10a2ef1c98b9ac8b57f1117a72bf2c7b9e7c1ac4d77fc94ca"),
+		B:  newBigInt("0x3df91610a83441caea9863bc2ded5d5aa8253aa10a2ef1c98b9ac8b57f1117a72bf2c7b9e7c1ac4d77fc94cadc083e67984050b75ebae5dd2809bd638016f723"),
+		Gx: newBigInt("0x81aee4bdd82ed9645a21322e9c4c6a9385ed9f70b5d916c1b43b62eef4d0098eff3b1f78e2d0d48d50d1687b93b97d5f7c6d5047406a5e688b352209bcb9f822"),
+		Gy: newBigInt("0x7dde385d566332ecc0eabfa9cf7822fdf209f70024a57b1aa000c55b881f8111b2dcde494a5f485e5bca4bd88a2763aed1ca2b2fa8f0540678cd1e0f3ad80892"),
+		N:  newBigInt("0xaadd9db8dbe9c48b3fd4e6ae33c9fc07cb308db3b3c9d20ed6639cca70330870553e5c414ca92619418661197fac10471db1d381085ddaddb58796829ca90069"),
+		H:  big.NewInt(1),
+	},
 	"prime256v1": {
 		// https://neuromancer.sk/std/x962/prime256v1
+		Name:    "prime256v1",
+		OID:     asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7},
+		BitSize: 256,
+
 		P:  newBigInt("0xffffffff00000001000000000000000000000000ffffffffffffffffffffffff"),
 		A:  newBigInt("0xffffffff00000001000000000000000000000000fffffffffffffffffffffffc"),
 		B:  newBigInt("0x5ac635d8aa3a93e7b3ebbd55769886bc651d06b0cc53b0f63bce3c3e27d2604b"),
 		Gx: newBigInt("0x6b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c296"),
 		Gy: newBigInt("0x4fe342e2fe1a7f9b8ee7eb4a7c0f9e162bce33576b315ececbb6406837bf51f5"),
 		N:  newBigInt("0xffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551"),
+		H:  big.NewInt(1),
+	},
+	"secp384r1": {
+		// https://neuromancer.sk/std/secg/secp384r1
+		Name:    "secp384r1",
+		OID:     asn1.ObjectIdentifier{1, 3, 132, 0, 34},
+		BitSize: 384,
+
+		P:  newBigInt("0xfffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffff0000000000000000ffffffff"),
+		A:  newBigInt("0xfffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffff0000000000000000fffffffc"),
+		B:  newBigInt("0xb3312fa7e23ee7e4988e056be3f82d19181d9c6efe8141120314088f5013875ac656398d8a2ed19d2a85c8edd3ec2aef"),
+		Gx: newBigInt("0xaa87ca22be8b05378eb1c71ef320ad746e1d3b628ba79b9859f741e082542a385502f25dbf55296c3a545e3872760ab7"),
+		Gy: newBigInt("0x3617de4a96262c6f5d9e98bf9292dc29f8f41dbd289a147ce9da3113b5f0b8c00a60b1ce1d7e819d7a431d7c90ea0e5f"),
+		N:  newBigInt("0xffffffffffffffffffffffffffffffffffffffffffffffffc7634d81f4372ddf581a0db248b0a77aecec196accc52973"),
+		H:  big.NewInt(1),
+	},
+	"secp521r1": {
+		// https://neuromancer.sk/std/secg/secp521r1
+		Name:    "secp521r1",
+		OID:     asn1.ObjectIdentifier{1, 3, 132, 0, 35},
+		BitSize: 521,
+
+		P:  newBigInt("0x1ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"),
+		A:  newBigInt("0x1fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffc"),
+		B:  newBigInt("0x0051953eb9618e1c9a1f929a21a0b68540eea2da725b99b315f3b8b489918ef109e156193951ec7e937b1652c0bd3bb1bf073573df883d2c34f1ef451fd46b503f00"),
+		Gx: newBigInt("0x00c6858e06b70404e9cd9e3ecb662395b4429c648139053fb521f828af606b4d3dbaa14b5e77efe75928fe1dc127a2ffa8de3348b3c1856a429bf97e7e31c2e5bd66"),
+		Gy: newBigInt("0x011839296a789a3bc0045c8a5fb42c7d1bd998f54449579b446817afbd17273e662c97ee72995ef42640c550b9013fad0761353c7086a272c24088be94769fd16650"),
+		N:  newBigInt("0x01fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffa51868783bf2f966b7fcc0148f709a5d03bb5c9b8899c47aebb6fb71e91386409"),
+		H:  big.NewInt(1),
 	},
 	"secp256k1": {
 		// https://neuromancer.sk/std/secg/secp256k1
+		Name:    "secp256k1",
+		OID:     asn1.ObjectIdentifier{1, 3, 132, 0, 10},
+		BitSize: 256,
+
 		P:  newBigInt("0xfffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f"),
 		A:  big.NewInt(0),
 		B:  big.NewInt(7),
 		Gx: newBigInt("0x79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"),
 		Gy: newBigInt("0x483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"),
 		N:  newBigInt("0xfffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141"),
+		H:  big.NewInt(1),
+
+		// https://github.com/bitcoin-core/secp256k1/blob/master/src/scalar_impl.h
+		glv: &glvParams{
+			beta:   newBigInt("0x7ae96a2b657c07106e64479eac3434e99cf0497512f58995c1396c28719501ee"),
+			lambda: newBigInt("0x5363ad4cc05c30e0a5261c028812645a122e22ea20816678df02967c1b23bd72"),
+			a1:     newBigInt("0x3086d221a7d46bcde86c90e49284eb15"),
+			b1:     newBigInt("-0xe4437ed6010e88286f547fa90abfe4c3"),
+			a2:     newBigInt("0x114ca50f7a8e2f3f657c1108d9d44cfd8"),
+			b2:     newBigInt("0x3086d221a7d46bcde86c90e49284eb15"),
+		},
 	},
 }
 
-var supportedCurves = func() []string {
-	var rv []string
-	for c := range curves {
+var curvesMutex sync.RWMutex
+
+func CurveByName(name string) (*Curve, error) {
+	curvesMutex.RLock()
+	defer curvesMutex.RUnlock()
+
+	if c, ok := curves[name]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("unsupported curve: %s", name)
+}
+
+// Whether c itself (rather than an equal copy) is in the registry
+func (c *Curve) registered() bool {
+	curvesMutex.RLock()
+	defer curvesMutex.RUnlock()
+
+	return curves[c.Name] == c
+}
+
+func CurveByOID(oid asn1.ObjectIdentifier) (*Curve, error) {
+	curvesMutex.RLock()
+	defer curvesMutex.RUnlock()
+
+	for _, c := range curves {
+		if c.OID != nil && c.OID.Equal(oid) {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported curve oid %s", oid)
+}
+
+// Registered curves sorted by name
+func Curves() []*Curve {
+	curvesMutex.RLock()
+	defer curvesMutex.RUnlock()
+
+	rv := make([]*Curve, 0, len(curves))
+	for _, c := range curves {
 		rv = append(rv, c)
 	}
+	slices.SortFunc(rv, func(a, b *Curve) int { return strings.Compare(a.Name, b.Name) })
 	return rv
-}()
+}
+
+// Adds a custom curve to the registry (making it usable by name or OID, eg when parsing keys) after validating it.
+// BitSize and H are filled in when unset, the latter is derived from the Hasse bound which requires n > 4 * sqrt(p).
+func RegisterCurve(c *Curve) error {
+	if c.Name == "" {
+		return errors.New("missing curve name")
+	}
+
+	// Validate and derive the defaults on a copy, c is only updated once registered
+	d := *c
+	if d.BitSize == 0 && d.P != nil {
+		d.BitSize = d.P.BitLen()
+	}
+
+	if err := d.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", c.Name, err)
+	}
+
+	if d.H == nil {
+		// #E in [p + 1 - 2 * sqrt(p), p + 1 + 2 * sqrt(p)] so h = floor((sqrt(p) + 1)^2 / n) when n > 4 * sqrt(p)
+		sqrtP := new(big.Int).Sqrt(d.P)
+		if new(big.Int).Mul(d.N, d.N).Cmp(new(big.Int).Lsh(d.P, 4)) <= 0 {
+			return fmt.Errorf("%s: cofactor required, n <= 4 * sqrt(p)", c.Name)
+		}
+		h := new(big.Int).Add(sqrtP, big.NewInt(2)) // Rounded up
+		h.Mul(h, h).Div(h, d.N)
+		for h.Sign() == 1 && !hasseBound(d.P, new(big.Int).Mul(h, d.N)) {
+			h.Sub(h, big.NewInt(1))
+		}
+		if h.Sign() != 1 {
+			return fmt.Errorf("%s: no cofactor within the hasse bound", c.Name)
+		}
+		d.H = h
+	}
+
+	curvesMutex.Lock()
+	defer curvesMutex.Unlock()
+
+	if _, ok := curves[c.Name]; ok {
+		return fmt.Errorf("%s: curve already registered", c.Name)
+	}
+	if c.OID != nil {
+		for _, e := range curves {
+			if e.OID != nil && e.OID.Equal(c.OID) {
+				return fmt.Errorf("%s: oid %s already registered to %s", c.Name, c.OID, e.Name)
+			}
+		}
+	}
+
+	c.BitSize, c.H = d.BitSize, d.H
+	curves[c.Name] = c
+	return nil
+}
+
+// Checks the domain parameters per SEC1 (https://www.secg.org/sec1-v2.pdf) section 3.1.1.2.1,
+// excluding the checks for known weak curves (see Audit). H may be nil if the cofactor is unknown.
+func (c *Curve) Validate() error {
+	if c.P == nil || c.A == nil || c.B == nil || c.Gx == nil || c.Gy == nil || c.N == nil {
+		return errors.New("missing curve parameters")
+	}
+
+	// Verify p is an odd prime and the other parameters are reduced mod p
+	if c.P.Cmp(big.NewInt(3)) < 0 || c.P.Bit(0) == 0 || !c.P.ProbablyPrime(20) {
+		return errors.New("p not an odd prime")
+	}
+	if c.BitSize != 0 && c.BitSize != c.P.BitLen() {
+		return errors.New("bit size mismatch with p")
+	}
+	for _, v := range []*big.Int{c.A, c.B, c.Gx, c.Gy} {
+		if v.Sign() == -1 || v.Cmp(c.P) >= 0 {
+			return errors.New("parameter out of range [0, p)")
+		}
+	}
+
+	if c.singular() {
+		return errors.New("singular curve")
+	}
+
+	if c.N.Cmp(big.NewInt(1)) <= 0 || !c.N.ProbablyPrime(20) {
+		return errors.New("n not prime")
+	}
+
+	// Verify the number of points h * n satisfies the Hasse bound, or at least n does when h is unknown
+	if c.H != nil {
+		if c.H.Sign() != 1 {
+			return errors.New("cofactor not positive")
+		}
+		if !hasseBound(c.P, new(big.Int).Mul(c.H, c.N)) {
+			return errors.New("h * n outside the hasse bound")
+		}
+	} else {
+		maxPoints := new(big.Int).Add(c.P, big.NewInt(1))
+		maxPoints.Add(maxPoints, new(big.Int).Sqrt(new(big.Int).Lsh(c.P, 2)))
+		if c.N.Cmp(maxPoints) > 0 {
+			return errors.New("n outside the hasse bound")
+		}
+	}
+
+	g := &Point{X: c.Gx, Y: c.Gy, Curve: c}
+	if !g.OnCurve() {
+		return errors.New("g not on curve")
+	}
+
+	// Verify n * G = O (point at infinity)
+	if !newJacobianPoint(g).multiply(c.N).atInf() {
+		return errors.New("n * g != o")
+	}
+
+	return nil
+}
+
+// Whether (4a^3 + 27b^2) % p = 0, ie the curve has a cusp or node
+func (c *Curve) singular() bool {
+	v := new(big.Int).Exp(c.A, big.NewInt(3), c.P)
+	v.Mul(v, big.NewInt(4))
+	w := new(big.Int).Exp(c.B, big.NewInt(2), c.P)
+	w.Mul(w, big.NewInt(27))
+	return v.Add(v, w).Mod(v, c.P).Sign() == 0
+}
+
+// Hasse's theorem: |p + 1 - #E| <= 2 * sqrt(p), ie (p + 1 - #E)^2 <= 4p
+func hasseBound(p, points *big.Int) bool {
+	t := new(big.Int).Add(p, big.NewInt(1))
+	t.Sub(t, points)
+	return t.Mul(t, t).Cmp(new(big.Int).Lsh(p, 2)) <= 0
+}
@@ -1,12 +1,24 @@
 package ecdsa_tools
 
 import (
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"fmt"
 	"math/big"
 	"testing"
 )
 
 func TestCurves(t *testing.T) {
 	for name, curve := range curves {
+		if curve.Name != name || curve.BitSize != curve.P.BitLen() || curve.H == nil {
+			t.Errorf("%s: name, bit size or cofactor mismatch", name)
+		}
+		if err := curve.Validate(); err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+
 		// Verify n < p
 		if curve.N.Cmp(curve.P) != -1 {
 			t.Errorf("%s: n >= p", name)
@@ -36,26 +48,237 @@ func TestCurves(t *testing.T) {
 		}
 
 		// Verify n * G = O (point at infinity)
+		if q, err := g.TryMultiply(curve.N); err != nil {
+			t.Errorf("%s: n * g: %s", name, err)
+		} else if err := q.Validate(); !errors.Is(err, ErrPointAtInfinity) {
+			t.Errorf("%s: n * g != o", name)
+		}
+	}
+}
+
+func TestCurvesStdLib(t *testing.T) {
+	table := map[string]elliptic.Curve{
+		"prime256v1": elliptic.P256(),
+		"secp384r1":  elliptic.P384(),
+		"secp521r1":  elliptic.P521(),
+	}
+
+	for name, stdCurve := range table {
+		curve := curves[name]
+		params := stdCurve.Params()
+
+		if curve.P.Cmp(params.P) != 0 || curve.B.Cmp(params.B) != 0 ||
+			curve.Gx.Cmp(params.Gx) != 0 || curve.Gy.Cmp(params.Gy) != 0 ||
+			curve.N.Cmp(params.N) != 0 {
+			t.Errorf("%s: parameters mismatch", name)
+		}
+
+		// The standard library curves all have a = -3
+		if new(big.Int).Add(curve.A, big.NewInt(3)).Cmp(curve.P) != 0 {
+			t.Errorf("%s: a != -3", name)
+		}
+	}
+}
 
-		multiplyGByN := func(g *Point, n *big.Int) (err error) {
-			defer func() {
-				if r := recover(); r != nil {
-					if e, ok := r.(error); ok {
-						err = e
-					}
-				}
-			}()
+func TestBrainpoolRFC7027(t *testing.T) {
+	// RFC 7027 appendix A (ECDH test vectors)
+	table := []struct {
+		curve            string
+		dA, xA, yA       string
+		dB, xB, yB       string
+		xShared, yShared string
+	}{
+		{
+			"brainpoolP256r1",
+			"0x81db1ee100150ff2ea338d708271be38300cb54241d79950f77b063039804f1d",
+			"0x44106e913f92bc02a1705d9953a8414db95e1aaa49e81d9e85f929a8e3100be5",
+			"0x8ab4846f11caccb73ce49cbdd120f5a900a69fd32c272223f789ef10eb089bdc",
+			"0x55e40bc41e37e3e2ad25c3c6654511ffa8474a91a0032087593852d3e7d76bd3",
+			"0x8d2d688c6cf93e1160ad04cc4429117dc2c41825e1e9fca0addd34e6f1b39f7b",
+			"0x990c57520812be512641e47034832106bc7d3e8dd0e4c7f1136d7006547cec6a",
+			"0x89afc39d41d3b327814b80940b042590f96556ec91e6ae7939bce31f3a18bf2b",
+			"0x49c27868f4eca2179bfd7d59b1e3bf34c1dbde61ae12931648f43e59632504de",
+		},
+		{
+			"brainpoolP384r1",
+			"0x1e20f5e048a5886f1f157c74e91bde2b98c8b52d58e5003d57053fc4b0bd65d6f15eb5d1ee1610df870795143627d042",
+			"0x68b665dd91c195800650cdd363c625f4e742e8134667b767b1b476793588f885ab698c852d4a6e77a252d6380fcaf068",
+			"0x55bc91a39c9ec01dee36017b7d673a931236d2f1f5c83942d049e3fa20607493e0d038ff2fd30c2ab67d15c85f7faa59",
+			"0x032640bc6003c59260f7250c3db58ce647f98e1260acce4acda3dd869f74e01f8ba5e0324309db6a9831497abac96670",
+			"0x4d44326f269a597a5b58bba565da5556ed7fd9a8a9eb76c25f46db69d19dc8ce6ad18e404b15738b2086df37e71d1eb4",
+			"0x62d692136de56cbe93bf5fa3188ef58bc8a3a0ec6c1e151a21038a42e9185329b5b275903d192f8d4e1f32fe9cc78c48",
+			"0x0bd9d3a7ea0b3d519d09d8e48d0785fb744a6b355e6304bc51c229fbbce239bbadf6403715c35d4fb2a5444f575d4f42",
+			"0x0df213417ebe4d8e40a5f76f66c56470c489a3478d146decf6df0d94bae9e598157290f8756066975f1db34b2324b7bd",
+		},
+		{
+			"brainpoolP512r1",
+			"0x16302ff0dbbb5a8d733dab7141c1b45acbc8715939677f6a56850a38bd87bd59b09e80279609ff333eb9d4c061231fb26f92eeb04982a5f1d1764cad57665422",
+			"0x0a420517e406aac0acdce90fcd71487718d3b953efd7fbec5f7f27e28c6149999397e91e029e06457db2d3e640668b392c2a7e737a7f0bf04436d11640fd09fd",
+			"0x72e6882e8db28aad36237cd25d580db23783961c8dc52dfa2ec138ad472a0fcef3887cf62b623b2a87de5c588301ea3e5fc269b373b60724f5e82a6ad147fde7",
+			"0x230e18e1bcc88a362fa54e4ea3902009292f7f8033624fd471b5d8ace49d12cfabbc19963dab8e2f1eba00bffb29e4d72d13f2224562f405cb80503666b25429",
+			"0x9d45f66de5d67e2e6db6e93a59ce0bb48106097ff78a081de781cdb31fce8ccbaaea8dd4320c4119f1e9cd437a2eab3731fa9668ab268d871deda55a5473199f",
+			"0x2fdc313095bcdd5fb3a91636f07a959c8e86b5636a1e930e8396049cb481961d365cc11453a06c719835475b12cb52fc3c383bce35e27ef194512b71876285fa",
+			"0xa7927098655f1f9976fa50a9d566865dc530331846381c87256baf3226244b76d36403c024d7bbf0aa0803eaff405d3d24f11a9b5c0bef679fe1454b21c4cd1f",
+			"0x7db71c3def63212841c463e881bdcf055523bd368240e6c3143bd8def8b3b3223b95e0f53082ff5e412f4222537a43df1c6d25729ddb51620a832be6a26680a2",
+		},
+	}
+
+	for _, entry := range table {
+		curve := curves[entry.curve]
+
+		privkeyA := &PrivKey{D: newBigInt(entry.dA), Curve: curve}
+		privkeyB := &PrivKey{D: newBigInt(entry.dB), Curve: curve}
+
+		pubkeyA := privkeyA.CalcPubKey()
+		if pubkeyA.E.X.Cmp(newBigInt(entry.xA)) != 0 || pubkeyA.E.Y.Cmp(newBigInt(entry.yA)) != 0 {
+			t.Errorf("%s: pubkey a mismatch", entry.curve)
+		}
 
-			g.Multiply(n)
-			return
+		pubkeyB := privkeyB.CalcPubKey()
+		if pubkeyB.E.X.Cmp(newBigInt(entry.xB)) != 0 || pubkeyB.E.Y.Cmp(newBigInt(entry.yB)) != 0 {
+			t.Errorf("%s: pubkey b mismatch", entry.curve)
 		}
 
-		if err := multiplyGByN(g, curve.N); err != nil {
-			if errMsg := err.Error(); errMsg != "multiplied point not on curve" {
-				t.Errorf("%s: n * g ?= o: %s", name, errMsg)
+		// The shared secret dA * QB = dB * QA
+		for _, shared := range []*Point{pubkeyB.E.Multiply(privkeyA.D), pubkeyA.E.MultiplyConstantTime(privkeyB.D)} {
+			if shared.X.Cmp(newBigInt(entry.xShared)) != 0 || shared.Y.Cmp(newBigInt(entry.yShared)) != 0 {
+				t.Errorf("%s: shared secret mismatch", entry.curve)
 			}
-		} else {
-			t.Errorf("%s: n * g != o", name)
+		}
+
+		// Signatures made with the test keys verify
+		msg := []byte("Message for ECDSA signing")
+		hashFunc := func(data []byte) []byte {
+			rv := sha256.Sum256(data)
+			return rv[:]
+		}
+		sig, err := privkeyA.SignMessage(msg, hashFunc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pubkeyA.Verify(sig.R, sig.S, msg, hashFunc) {
+			t.Errorf("%s: verification failed", entry.curve)
+		}
+	}
+}
+
+func TestCurveRegistry(t *testing.T) {
+	curve, err := CurveByName("secp256k1")
+	if err != nil || curve != curves["secp256k1"] {
+		t.Fatal("secp256k1 by name")
+	}
+	if curve, err := CurveByOID(asn1.ObjectIdentifier{1, 3, 132, 0, 10}); err != nil || curve.Name != "secp256k1" {
+		t.Fatal("secp256k1 by oid")
+	}
+	if _, err := CurveByName("secp224r1"); err == nil {
+		t.Error("unexpected secp224r1")
+	}
+
+	list := Curves()
+	if len(list) != len(curves) {
+		t.Fatal("curves length mismatch")
+	}
+	for i := 1; i < len(list); i++ {
+		if list[i-1].Name >= list[i].Name {
+			t.Error("curves not sorted")
+		}
+	}
+
+	// secp256k1 under another name and oid
+	custom := &Curve{
+		Name: "custom",
+		OID:  asn1.ObjectIdentifier{1, 2, 3, 4},
+		P:    curve.P, A: curve.A, B: curve.B, Gx: curve.Gx, Gy: curve.Gy, N: curve.N,
+	}
+	if err := RegisterCurve(custom); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		curvesMutex.Lock()
+		delete(curves, "custom")
+		curvesMutex.Unlock()
+	})
+
+	if custom.BitSize != 256 || custom.H.Cmp(big.NewInt(1)) != 0 {
+		t.Error("defaults not filled in")
+	}
+	if c, err := CurveByOID(asn1.ObjectIdentifier{1, 2, 3, 4}); err != nil || c != custom {
+		t.Error("custom by oid")
+	}
+
+	// Keys on registered curves round trip
+	privkey := &PrivKey{D: big.NewInt(0xdeadbeef), Curve: custom}
+	der, err := privkey.MarshalSEC1()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed, err := NewPrivKeyFromSEC1(der); err != nil || parsed.Curve != custom || parsed.D.Cmp(privkey.D) != 0 {
+		t.Error("custom curve privkey round trip")
+	}
+
+	invalid := []*Curve{
+		{Name: "custom", P: curve.P, A: curve.A, B: curve.B, Gx: curve.Gx, Gy: curve.Gy, N: curve.N},
+		{Name: "oid", OID: custom.OID, P: curve.P, A: curve.A, B: curve.B, Gx: curve.Gx, Gy: curve.Gy, N: curve.N},
+		{Name: "", P: curve.P, A: curve.A, B: curve.B, Gx: curve.Gx, Gy: curve.Gy, N: curve.N},
+		{Name: "missing", P: curve.P, A: curve.A, B: curve.B, Gx: curve.Gx, Gy: curve.Gy},
+		{Name: "p", P: new(big.Int).Add(curve.P, big.NewInt(2)), A: curve.A, B: curve.B, Gx: curve.Gx, Gy: curve.Gy, N: curve.N},
+		{Name: "singular", P: curve.P, A: big.NewInt(0), B: big.NewInt(0), Gx: curve.Gx, Gy: curve.Gy, N: curve.N},
+		{Name: "g", P: curve.P, A: curve.A, B: curve.B, Gx: curve.Gx, Gy: curve.Gx, N: curve.N},
+		{Name: "n", P: curve.P, A: curve.A, B: curve.B, Gx: curve.Gx, Gy: curve.Gy, N: curves["prime256v1"].N},
+	}
+	for _, c := range invalid {
+		if err := RegisterCurve(c); err == nil {
+			t.Errorf("%q: registered invalid curve", c.Name)
+		}
+		if c.BitSize != 0 || c.H != nil {
+			t.Errorf("%q: failed registration modified the curve", c.Name)
+		}
+	}
+}
+
+func TestCurveRegistryConcurrent(t *testing.T) {
+	secp256k1, err := CurveByName("secp256k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Run with -race, registrations must not race with lookups of the built-in curves
+	const count = 8
+	done := make(chan error)
+	go func() {
+		defer close(done)
+		for i := 0; i < count; i++ {
+			c := *secp256k1
+			c.Name, c.OID, c.glv = fmt.Sprintf("concurrent%d", i), nil, nil
+			if err := RegisterCurve(&c); err != nil {
+				done <- err
+				return
+			}
+		}
+	}()
+	t.Cleanup(func() {
+		curvesMutex.Lock()
+		for i := 0; i < count; i++ {
+			delete(curves, fmt.Sprintf("concurrent%d", i))
+		}
+		curvesMutex.Unlock()
+	})
+
+	for running := true; running; {
+		select {
+		case err, ok := <-done:
+			if ok {
+				t.Fatal(err)
+			}
+			running = false
+		default:
+		}
+
+		if _, err := NewPrivKeyBitcoin("deadbeef"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewRandomPrivKeyViaStdLib("prime256v1"); err != nil {
+			t.Fatal(err)
 		}
 	}
 }
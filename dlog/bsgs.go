@@ -0,0 +1,60 @@
+package dlog
+
+import (
+	"errors"
+	"math/big"
+
+	ecdsa "github.com/jo-makar/ecdsa-tools"
+)
+
+// Baby-step giant-step (Shanks): with m = ceil(sqrt(n)) write k = i * m + j for i, j in [0, m).
+// Storing the baby steps j * P then Q - i * (m * P) = j * P for some i (the giant steps),
+// O(sqrt(n)) time and memory.
+
+const maxBSGSBits = 52 // Limits the table to about 2^26 entries
+
+var ErrNotFound = errors.New("discrete log not found")
+
+// Finds k in [0, n) with q = k * p, n being the order of p (the curve order if nil)
+func BSGS(p, q *ecdsa.Point, n *big.Int) (*big.Int, error) {
+	g, gp, gq, n, err := setup(p, q, n)
+	if err != nil {
+		return nil, err
+	}
+	return g.bsgs(gp, gq, n)
+}
+
+func (g *group) bsgs(p, q *point, n *big.Int) (*big.Int, error) {
+	if n.BitLen() > maxBSGSBits {
+		return nil, errors.New("order too large for baby-step giant-step")
+	}
+
+	m := new(big.Int).Sqrt(n)
+	if new(big.Int).Mul(m, m).Cmp(n) < 0 {
+		m.Add(m, big.NewInt(1))
+	}
+	steps := m.Int64()
+
+	baby := make(map[string]int64, steps)
+	r := infinity
+	for j := int64(0); j < steps; j++ {
+		if _, ok := baby[r.key()]; !ok {
+			baby[r.key()] = j
+		}
+		r = g.add(r, p)
+	}
+
+	// r = m * P, subtracted at each giant step
+	giant := g.neg(r)
+	r = q
+	for i := int64(0); i <= steps; i++ {
+		if j, ok := baby[r.key()]; ok {
+			k := new(big.Int).Mul(big.NewInt(i), m)
+			k.Add(k, big.NewInt(j))
+			return k.Mod(k, n), nil
+		}
+		r = g.add(r, giant)
+	}
+
+	return nil, ErrNotFound
+}
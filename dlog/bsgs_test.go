@@ -0,0 +1,76 @@
+package dlog
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	ecdsa "github.com/jo-makar/ecdsa-tools"
+)
+
+func newInt(s string) *big.Int {
+	i, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid value")
+	}
+	return i
+}
+
+// Toy curves of prime order found with Curve.ComputeOrder
+var (
+	curve24 = &ecdsa.Curve{
+		P: newInt("11020039"), A: newInt("525680"), B: newInt("9961216"),
+		Gx: newInt("5882566"), Gy: newInt("5084559"), N: newInt("11016871"), H: big.NewInt(1),
+	}
+	curve40 = &ecdsa.Curve{
+		P: newInt("655662405661"), A: newInt("372445168333"), B: newInt("539408746126"),
+		Gx: newInt("610348958414"), Gy: newInt("543576114150"), N: newInt("655663378067"), H: big.NewInt(1),
+	}
+)
+
+// Random k and q = k * G
+func randomKey(t *testing.T, curve *ecdsa.Curve) (*big.Int, *ecdsa.Point, *ecdsa.Point) {
+	k, err := rand.Int(rand.Reader, curve.N)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := &ecdsa.Point{X: curve.Gx, Y: curve.Gy, Curve: curve}
+	if k.Sign() == 0 {
+		return k, g, &ecdsa.Point{AtInf: true, Curve: curve}
+	}
+	return k, g, g.Multiply(k)
+}
+
+func TestBSGS(t *testing.T) {
+	// A single run on the larger curve, its table has about 2^20 entries
+	for _, curve := range []*ecdsa.Curve{curve24, curve24, curve24, curve40} {
+		k, g, q := randomKey(t, curve)
+
+		actual, err := BSGS(g, q, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual.Cmp(k) != 0 {
+			t.Errorf("%s != %s", actual, k)
+		}
+	}
+
+	g := &ecdsa.Point{X: curve24.Gx, Y: curve24.Gy, Curve: curve24}
+	if k, err := BSGS(g, &ecdsa.Point{AtInf: true, Curve: curve24}, nil); err != nil || k.Sign() != 0 {
+		t.Errorf("infinity: %v, %v", k, err)
+	}
+	if k, err := BSGS(g, g.Negate(), nil); err != nil || k.Cmp(new(big.Int).Sub(curve24.N, big.NewInt(1))) != 0 {
+		t.Errorf("negation: %v, %v", k, err)
+	}
+
+	// The order must be that of the base point
+	if _, err := BSGS(g, g, big.NewInt(1000)); err == nil {
+		t.Error("wrong order accepted")
+	}
+
+	other := &ecdsa.Point{X: curve40.Gx, Y: curve40.Gy, Curve: curve40}
+	if _, err := BSGS(g, other, nil); err == nil {
+		t.Error("points on different curves accepted")
+	}
+}
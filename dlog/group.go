@@ -0,0 +1,150 @@
+package dlog
+
+import (
+	"errors"
+	"math/big"
+
+	ecdsa "github.com/jo-makar/ecdsa-tools"
+)
+
+// Lightweight affine point arithmetic for the solvers, which routinely reach the point at infinity (a nil point)
+// and have no need for the on curve checks made by ecdsa.Point.
+
+type point struct {
+	x, y *big.Int // Both nil for the point at infinity
+}
+
+func (u *point) atInf() bool {
+	return u.x == nil
+}
+
+// Map key, equal for equal points
+func (u *point) key() string {
+	if u.atInf() {
+		return ""
+	}
+	return string(append(u.x.Bytes(), byte(u.y.Bit(0))))
+}
+
+type group struct {
+	curve *ecdsa.Curve
+}
+
+var infinity = &point{}
+
+func (g *group) fromPoint(u *ecdsa.Point) *point {
+	if u.AtInf {
+		return infinity
+	}
+	return &point{x: new(big.Int).Mod(u.X, g.curve.P), y: new(big.Int).Mod(u.Y, g.curve.P)}
+}
+
+func (g *group) toPoint(u *point) *ecdsa.Point {
+	if u.atInf() {
+		return &ecdsa.Point{AtInf: true, Curve: g.curve}
+	}
+	return &ecdsa.Point{X: new(big.Int).Set(u.x), Y: new(big.Int).Set(u.y), Curve: g.curve}
+}
+
+func (g *group) equal(u, v *point) bool {
+	if u.atInf() || v.atInf() {
+		return u.atInf() && v.atInf()
+	}
+	return u.x.Cmp(v.x) == 0 && u.y.Cmp(v.y) == 0
+}
+
+func (g *group) neg(u *point) *point {
+	if u.atInf() || u.y.Sign() == 0 {
+		return u
+	}
+	return &point{x: u.x, y: new(big.Int).Sub(g.curve.P, u.y)}
+}
+
+func (g *group) add(u, v *point) *point {
+	if u.atInf() {
+		return v
+	}
+	if v.atInf() {
+		return u
+	}
+
+	p := g.curve.P
+	lambda := new(big.Int)
+
+	if u.x.Cmp(v.x) == 0 {
+		if u.y.Cmp(v.y) != 0 || u.y.Sign() == 0 {
+			// u = -v (including points of order two)
+			return infinity
+		}
+
+		// lambda = (3x^2 + a) / 2y
+		lambda.Mul(u.x, u.x)
+		lambda.Mul(lambda, big.NewInt(3))
+		lambda.Add(lambda, g.curve.A)
+		lambda.Mul(lambda, new(big.Int).ModInverse(new(big.Int).Lsh(u.y, 1), p))
+	} else {
+		// lambda = (yv - yu) / (xv - xu)
+		lambda.Sub(v.y, u.y)
+		lambda.Mul(lambda, new(big.Int).ModInverse(new(big.Int).Sub(v.x, u.x), p))
+	}
+	lambda.Mod(lambda, p)
+
+	x := new(big.Int).Mul(lambda, lambda)
+	x.Sub(x, u.x)
+	x.Sub(x, v.x)
+	x.Mod(x, p)
+
+	y := new(big.Int).Sub(u.x, x)
+	y.Mul(y, lambda)
+	y.Sub(y, u.y)
+	y.Mod(y, p)
+
+	return &point{x: x, y: y}
+}
+
+// k * u for k >= 0
+func (g *group) mul(k *big.Int, u *point) *point {
+	r := infinity
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		r = g.add(r, r)
+		if k.Bit(i) == 1 {
+			r = g.add(r, u)
+		}
+	}
+	return r
+}
+
+// a * p + b * q
+func (g *group) combine(a *big.Int, p *point, b *big.Int, q *point) *point {
+	return g.add(g.mul(a, p), g.mul(b, q))
+}
+
+// Checks the inputs common to the solvers, n defaults to the curve order
+func setup(p, q *ecdsa.Point, n *big.Int) (*group, *point, *point, *big.Int, error) {
+	if p.Curve != q.Curve && (p.Curve.P.Cmp(q.Curve.P) != 0 || p.Curve.A.Cmp(q.Curve.A) != 0 || p.Curve.B.Cmp(q.Curve.B) != 0) {
+		return nil, nil, nil, nil, errors.New("points not on same curve")
+	}
+
+	if n == nil {
+		n = p.Curve.N
+	}
+	if n == nil || n.Sign() != 1 {
+		return nil, nil, nil, nil, errors.New("missing order")
+	}
+
+	if !p.OnCurve() {
+		return nil, nil, nil, nil, errors.New("base point not on curve")
+	}
+	if !q.AtInf && !q.OnCurve() {
+		return nil, nil, nil, nil, errors.New("point not on curve")
+	}
+
+	g := &group{curve: p.Curve}
+	gp, gq := g.fromPoint(p), g.fromPoint(q)
+
+	if !g.mul(n, gp).atInf() {
+		return nil, nil, nil, nil, errors.New("n * p != o")
+	}
+
+	return g, gp, gq, n, nil
+}
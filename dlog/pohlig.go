@@ -0,0 +1,104 @@
+package dlog
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	ecdsa "github.com/jo-makar/ecdsa-tools"
+)
+
+// Pohlig-Hellman: when n = product(q_i^e_i) the discrete log reduces to ones in the subgroups of prime order q_i.
+// For each prime power k mod q^e is found one base q digit at a time, d_i being the log of
+// (n / q^(i+1)) * (Q - (d_0 + ... + d_i-1 * q^(i-1)) * P) to the base (n / q) * P (which has order q).
+// The results are combined with the CRT, so the work depends on the largest prime factor rather than n.
+
+const (
+	trialDivisionBound  = 1 << 20 // Prime factors of n above this are only found if n has a single one
+	maxBSGSSubgroupBits = 32      // Larger prime order subgroups use Pollard rho
+)
+
+// Finds k in [0, n) with q = k * p, n being the order of p (the curve order if nil).
+// Workers is passed on to PollardRho for large prime factors.
+func PohligHellman(ctx context.Context, p, q *ecdsa.Point, n *big.Int, workers int) (*big.Int, error) {
+	g, gp, gq, n, err := setup(p, q, n)
+	if err != nil {
+		return nil, err
+	}
+
+	factors, err := factorOrder(n)
+	if err != nil {
+		return nil, err
+	}
+
+	k, modulus := new(big.Int), big.NewInt(1)
+
+	for _, f := range factors {
+		// Base of order q
+		base := g.mul(new(big.Int).Div(n, f.prime), gp)
+
+		digits, power := new(big.Int), big.NewInt(1)
+		for i := 0; i < f.exponent; i++ {
+			// (n / q^(i+1)) * (Q - digits * P)
+			target := g.add(gq, g.neg(g.mul(digits, gp)))
+			target = g.mul(new(big.Int).Div(n, new(big.Int).Mul(power, f.prime)), target)
+
+			var d *big.Int
+			if f.prime.BitLen() <= maxBSGSSubgroupBits {
+				d, err = g.bsgs(base, target, f.prime)
+			} else {
+				d, err = g.rho(ctx, base, target, f.prime, workers)
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			digits.Add(digits, new(big.Int).Mul(d, power))
+			power.Mul(power, f.prime)
+		}
+
+		// CRT: k' = k + modulus * ((digits - k) / modulus mod q^e)
+		t := new(big.Int).Sub(digits, k)
+		t.Mul(t, new(big.Int).ModInverse(modulus, power))
+		t.Mod(t, power)
+		k.Add(k, t.Mul(t, modulus))
+		modulus.Mul(modulus, power)
+	}
+
+	return k.Mod(k, n), nil
+}
+
+type primePower struct {
+	prime    *big.Int
+	exponent int
+}
+
+func factorOrder(n *big.Int) ([]primePower, error) {
+	var factors []primePower
+	n = new(big.Int).Set(n)
+
+	for d := int64(2); d < trialDivisionBound && n.Cmp(big.NewInt(1)) > 0; d++ {
+		bd := big.NewInt(d)
+		if new(big.Int).Mul(bd, bd).Cmp(n) > 0 {
+			break
+		}
+
+		f := primePower{prime: bd}
+		for new(big.Int).Mod(n, bd).Sign() == 0 {
+			n.Div(n, bd)
+			f.exponent++
+		}
+		if f.exponent > 0 {
+			factors = append(factors, f)
+		}
+	}
+
+	if n.Cmp(big.NewInt(1)) > 0 {
+		if !n.ProbablyPrime(20) {
+			return nil, errors.New("order has more than one prime factor above the trial division bound")
+		}
+		factors = append(factors, primePower{prime: n, exponent: 1})
+	}
+
+	return factors, nil
+}
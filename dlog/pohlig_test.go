@@ -0,0 +1,54 @@
+package dlog
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	ecdsa "github.com/jo-makar/ecdsa-tools"
+)
+
+// Order n = 2^2 * 3 * 149 * 521 * 1811 * 28927 (with cofactor 4)
+var curve48 = &ecdsa.Curve{
+	P: newInt("195203253789433"), A: newInt("117307985481153"), B: newInt("38474200251868"),
+	Gx: newInt("15622020359134"), Gy: newInt("24361471403408"), N: newInt("48800815971756"), H: big.NewInt(4),
+}
+
+func TestPohligHellman(t *testing.T) {
+	for _, curve := range []*ecdsa.Curve{curve48, curve24} {
+		for i := 0; i < 8; i++ {
+			k, g, q := randomKey(t, curve)
+
+			actual, err := PohligHellman(context.Background(), g, q, nil, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual.Cmp(k) != 0 {
+				t.Errorf("%s != %s", actual, k)
+			}
+		}
+	}
+}
+
+func TestFactorOrder(t *testing.T) {
+	factors, err := factorOrder(curve48.N)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []primePower{{big.NewInt(2), 2}, {big.NewInt(3), 1}, {big.NewInt(149), 1},
+		{big.NewInt(521), 1}, {big.NewInt(1811), 1}, {big.NewInt(28927), 1}}
+	if len(factors) != len(expected) {
+		t.Fatalf("%v", factors)
+	}
+	for i := range factors {
+		if factors[i].prime.Cmp(expected[i].prime) != 0 || factors[i].exponent != expected[i].exponent {
+			t.Errorf("%v != %v", factors[i], expected[i])
+		}
+	}
+
+	// Two prime factors above the trial division bound
+	if _, err := factorOrder(new(big.Int).Mul(big.NewInt(1048583), big.NewInt(1048589))); err == nil {
+		t.Error("unexpected factorization")
+	}
+}
@@ -0,0 +1,172 @@
+package dlog
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"math/bits"
+	"runtime"
+
+	ecdsa "github.com/jo-makar/ecdsa-tools"
+)
+
+// Pollard's rho with distinguished points (van Oorschot and Wiener, https://doi.org/10.1007/PL00003816).
+//
+// Each worker follows a pseudo-random walk R -> R + M_j where j is derived from the x coordinate of R and
+// M_j = a_j * P + b_j * Q (an r-adding walk, Teske), tracking R = a * P + b * Q. Walks end at distinguished points
+// (those with the low bits of x zero) which are reported to a central table. Two walks reaching the same point
+// from a * P + b * Q = a' * P + b' * Q give k = (a - a') / (b' - b) mod n. Expected sqrt(pi * n / 2) steps
+// in total shared by the workers, with little memory.
+
+const rhoPartitions = 32
+
+// The distinguished point bits and the partition index above them come from the lowest word of x,
+// which also leaves 20 << maxDPBits within an int on 32-bit platforms
+const maxDPBits = min(40, bits.UintSize-8)
+
+// Finds k in [0, n) with q = k * p, n being the (prime) order of p (the curve order if nil).
+// Workers defaults to the number of CPUs when zero.
+func PollardRho(ctx context.Context, p, q *ecdsa.Point, n *big.Int, workers int) (*big.Int, error) {
+	g, gp, gq, n, err := setup(p, q, n)
+	if err != nil {
+		return nil, err
+	}
+	return g.rho(ctx, gp, gq, n, workers)
+}
+
+type distinguishedPoint struct {
+	key  string
+	a, b *big.Int
+}
+
+func (g *group) rho(ctx context.Context, p, q *point, n *big.Int, workers int) (*big.Int, error) {
+	if !n.ProbablyPrime(20) {
+		return nil, errors.New("order not prime")
+	}
+
+	// The walks are too short to be useful in tiny groups
+	if n.BitLen() < 16 {
+		return g.bsgs(p, q, n)
+	}
+
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	// Roughly 2^8 distinguished points expected before a collision
+	dpBits := min(max(0, n.BitLen()/2-8), maxDPBits)
+
+	as, bs := make([]*big.Int, rhoPartitions), make([]*big.Int, rhoPartitions)
+	ms := make([]*point, rhoPartitions)
+	for j := range ms {
+		var err error
+		if as[j], err = rand.Int(rand.Reader, n); err != nil {
+			return nil, err
+		}
+		if bs[j], err = rand.Int(rand.Reader, n); err != nil {
+			return nil, err
+		}
+		ms[j] = g.combine(as[j], p, bs[j], q)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	found := make(chan distinguishedPoint)
+	errs := make(chan error, workers)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			errs <- g.rhoWalks(ctx, p, q, n, as, bs, ms, dpBits, found)
+		}()
+	}
+
+	table := make(map[string]distinguishedPoint)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case err := <-errs:
+			if err == nil {
+				// The worker stopped due to cancellation
+				err = ctx.Err()
+			}
+			return nil, err
+
+		case dp := <-found:
+			prev, ok := table[dp.key]
+			if !ok {
+				table[dp.key] = dp
+				continue
+			}
+
+			// a + b * k = a' + b' * k so k = (a - a') / (b' - b)
+			db := new(big.Int).Sub(prev.b, dp.b)
+			db.Mod(db, n)
+			if db.Sign() == 0 {
+				continue
+			}
+
+			k := new(big.Int).Sub(dp.a, prev.a)
+			k.Mul(k, db.ModInverse(db, n))
+			k.Mod(k, n)
+
+			if !g.equal(g.mul(k, p), q) {
+				return nil, errors.New("inconsistent collision")
+			}
+			return k, nil
+		}
+	}
+}
+
+// Runs walks from random starting points until the context is cancelled
+func (g *group) rhoWalks(ctx context.Context, p, q *point, n *big.Int, as, bs []*big.Int, ms []*point,
+	dpBits int, found chan<- distinguishedPoint) error {
+
+	// Give up on walks that are likely stuck in a cycle without distinguished points
+	maxSteps := 20 << dpBits
+	dpMask := big.Word(1)<<dpBits - 1
+
+	for {
+		a, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			return err
+		}
+		b, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			return err
+		}
+		r := g.combine(a, p, b, q)
+
+		for step := 0; step < maxSteps; step++ {
+			if step%1024 == 0 && ctx.Err() != nil {
+				return nil
+			}
+
+			var low big.Word
+			if !r.atInf() {
+				if words := r.x.Bits(); len(words) > 0 {
+					low = words[0]
+				}
+			}
+
+			if low&dpMask == 0 {
+				select {
+				case found <- distinguishedPoint{key: r.key(), a: a, b: b}:
+				case <-ctx.Done():
+					return nil
+				}
+				break
+			}
+
+			j := (low >> dpBits) % rhoPartitions
+			r = g.add(r, ms[j])
+			a = new(big.Int).Add(a, as[j])
+			a.Mod(a, n)
+			b = new(big.Int).Add(b, bs[j])
+			b.Mod(b, n)
+		}
+	}
+}
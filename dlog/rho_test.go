@@ -0,0 +1,45 @@
+package dlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ecdsa "github.com/jo-makar/ecdsa-tools"
+)
+
+func TestPollardRho(t *testing.T) {
+	table := []struct {
+		curve   *ecdsa.Curve
+		workers int
+	}{
+		{curve24, 1},
+		{curve24, 4},
+		{curve40, 4},
+	}
+
+	for _, entry := range table {
+		k, g, q := randomKey(t, entry.curve)
+
+		actual, err := PollardRho(context.Background(), g, q, nil, entry.workers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual.Cmp(k) != 0 {
+			t.Errorf("%s != %s", actual, k)
+		}
+	}
+}
+
+func TestPollardRhoCancel(t *testing.T) {
+	// Far too large to solve before the deadline
+	curve := ecdsa.Curves()[0]
+	_, g, q := randomKey(t, curve)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if _, err := PollardRho(ctx, g, q, nil, 2); err != context.DeadlineExceeded {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,352 @@
+package ecdsa_tools
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+
+// RFC 5915 section 3
+type ecPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// RFC 5208 section 5
+type privateKeyInfo struct {
+	Version             int
+	PrivateKeyAlgorithm pkix.AlgorithmIdentifier
+	PrivateKey          []byte
+}
+
+// Whether the DER is a PrivateKeyInfo rather than an ECPrivateKey, ie whether the version is followed by the
+// privateKeyAlgorithm SEQUENCE (the ECPrivateKey privateKey being an OCTET STRING)
+func isPKCS8(der []byte) bool {
+	var outer, version, next asn1.RawValue
+	if _, err := asn1.Unmarshal(der, &outer); err != nil || outer.Tag != asn1.TagSequence {
+		return false
+	}
+	rest, err := asn1.Unmarshal(outer.Bytes, &version)
+	if err != nil {
+		return false
+	}
+	if _, err := asn1.Unmarshal(rest, &next); err != nil {
+		return false
+	}
+	return next.Class == asn1.ClassUniversal && next.Tag == asn1.TagSequence
+}
+
+// RFC 5280 section 4.1
+type subjectPublicKeyInfo struct {
+	Algorithm        pkix.AlgorithmIdentifier
+	SubjectPublicKey asn1.BitString
+}
+
+func NewPrivKeyFromPEM(data []byte) (*PrivKey, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("no private key pem block found")
+		}
+
+		switch block.Type {
+		case "EC PARAMETERS":
+			// Emitted by openssl ecparam -genkey, the named curve is repeated in the key itself
+			continue
+		case "EC PRIVATE KEY":
+			return NewPrivKeyFromSEC1(block.Bytes)
+		case "PRIVATE KEY":
+			return NewPrivKeyFromPKCS8(block.Bytes)
+		default:
+			return nil, fmt.Errorf("unexpected pem block type: %s", block.Type)
+		}
+	}
+}
+
+func NewPrivKeyFromSEC1(der []byte) (*PrivKey, error) {
+	return parseSEC1(der, nil)
+}
+
+func NewPrivKeyFromPKCS8(der []byte) (*PrivKey, error) {
+	var info privateKeyInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("PrivateKeyInfo: %w", err)
+	} else if len(rest) != 0 {
+		return nil, errors.New("PrivateKeyInfo: trailing bytes")
+	}
+
+	if info.Version != 0 {
+		return nil, fmt.Errorf("PrivateKeyInfo.version: expected 0, got %d", info.Version)
+	}
+
+	curve, err := parseAlgorithmIdentifier(info.PrivateKeyAlgorithm, "PrivateKeyInfo.privateKeyAlgorithm")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSEC1(info.PrivateKey, curve)
+}
+
+func NewPubKeyFromPEM(data []byte) (*PubKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no public key pem block found")
+	}
+	if block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("unexpected pem block type: %s", block.Type)
+	}
+
+	return NewPubKeyFromPKIX(block.Bytes)
+}
+
+func NewPubKeyFromPKIX(der []byte) (*PubKey, error) {
+	var info subjectPublicKeyInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("SubjectPublicKeyInfo: %w", err)
+	} else if len(rest) != 0 {
+		return nil, errors.New("SubjectPublicKeyInfo: trailing bytes")
+	}
+
+	curve, err := parseAlgorithmIdentifier(info.Algorithm, "SubjectPublicKeyInfo.algorithm")
+	if err != nil {
+		return nil, err
+	}
+
+	if info.SubjectPublicKey.BitLength%8 != 0 {
+		return nil, errors.New("SubjectPublicKeyInfo.subjectPublicKey: not a whole number of bytes")
+	}
+
+	e, err := parsePubKeyPoint(info.SubjectPublicKey.Bytes, curve)
+	if err != nil {
+		return nil, fmt.Errorf("SubjectPublicKeyInfo.subjectPublicKey: %w", err)
+	}
+
+	return &PubKey{E: e, Curve: curve}, nil
+}
+
+// The curve is taken from the enclosing structure (eg PKCS#8) when not nil
+func parseSEC1(der []byte, curve *Curve) (*PrivKey, error) {
+	var key ecPrivateKey
+	if rest, err := asn1.Unmarshal(der, &key); err != nil {
+		return nil, fmt.Errorf("ECPrivateKey: %w", err)
+	} else if len(rest) != 0 {
+		return nil, errors.New("ECPrivateKey: trailing bytes")
+	}
+
+	if key.Version != 1 {
+		return nil, fmt.Errorf("ECPrivateKey.version: expected 1, got %d", key.Version)
+	}
+
+	if len(key.NamedCurveOID) != 0 {
+		named, err := CurveByOID(key.NamedCurveOID)
+		if err != nil {
+			return nil, fmt.Errorf("ECPrivateKey.parameters: %w", err)
+		}
+		if curve != nil && !curve.Equals(named) {
+			return nil, errors.New("ECPrivateKey.parameters: curve mismatch with enclosing structure")
+		}
+		curve = named
+	}
+	if curve == nil {
+		return nil, errors.New("ECPrivateKey.parameters: missing named curve")
+	}
+
+	d := new(big.Int).SetBytes(key.PrivateKey)
+	if big.NewInt(1).Cmp(d) == 1 || d.Cmp(curve.N) >= 0 { // d < 1 || d >= curve.N
+		return nil, errors.New("ECPrivateKey.privateKey: value out of range")
+	}
+
+	privkey := &PrivKey{D: d, Curve: curve}
+
+	if key.PublicKey.BitLength != 0 {
+		if key.PublicKey.BitLength%8 != 0 {
+			return nil, errors.New("ECPrivateKey.publicKey: not a whole number of bytes")
+		}
+
+		e, err := parsePubKeyPoint(key.PublicKey.Bytes, curve)
+		if err != nil {
+			return nil, fmt.Errorf("ECPrivateKey.publicKey: %w", err)
+		}
+
+		// Verify pubkey = privkey * G
+		if !e.Equals(privkey.CalcPubKey().E) {
+			return nil, errors.New("ECPrivateKey.publicKey: pubkey privkey mismatch")
+		}
+	}
+
+	return privkey, nil
+}
+
+func parseAlgorithmIdentifier(algo pkix.AlgorithmIdentifier, field string) (*Curve, error) {
+	if !algo.Algorithm.Equal(oidPublicKeyECDSA) {
+		return nil, fmt.Errorf("%s.algorithm: expected %s, got %s", field, oidPublicKeyECDSA, algo.Algorithm)
+	}
+
+	var oid asn1.ObjectIdentifier
+	if rest, err := asn1.Unmarshal(algo.Parameters.FullBytes, &oid); err != nil {
+		// Explicit (specifiedCurve) parameters are not supported (RFC 5480 section 2.1.1)
+		return nil, fmt.Errorf("%s.parameters: expected named curve: %w", field, err)
+	} else if len(rest) != 0 {
+		return nil, fmt.Errorf("%s.parameters: trailing bytes", field)
+	}
+
+	curve, err := CurveByOID(oid)
+	if err != nil {
+		return nil, fmt.Errorf("%s.parameters: %w", field, err)
+	}
+
+	return curve, nil
+}
+
+// Any of the SEC1 point encodings (uncompressed, compressed or hybrid) are accepted
+func parsePubKeyPoint(b []byte, curve *Curve) (*Point, error) {
+	e, err := UnmarshalPoint(curve, b)
+	if err != nil {
+		return nil, err
+	}
+	if e.AtInf {
+		return nil, errors.New("pubkey is point at infinity")
+	}
+	return e, nil
+}
+
+func (p *PrivKey) MarshalSEC1() ([]byte, error) {
+	oid, err := curveOID(p.Curve)
+	if err != nil {
+		return nil, err
+	}
+	return p.marshalSEC1(oid)
+}
+
+func (p *PrivKey) MarshalSEC1PEM() ([]byte, error) {
+	der, err := p.MarshalSEC1()
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
+func (p *PrivKey) MarshalPKCS8() ([]byte, error) {
+	oid, err := curveOID(p.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	// The named curve is carried by the algorithm identifier rather than the ECPrivateKey (RFC 5915 section 3)
+	key, err := p.marshalSEC1(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	algo, err := algorithmIdentifier(oid)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(privateKeyInfo{
+		Version:             0,
+		PrivateKeyAlgorithm: algo,
+		PrivateKey:          key,
+	})
+}
+
+func (p *PrivKey) MarshalPKCS8PEM() ([]byte, error) {
+	der, err := p.MarshalPKCS8()
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+func (p *PubKey) MarshalPKIX() ([]byte, error) {
+	oid, err := curveOID(p.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	algo, err := algorithmIdentifier(oid)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := marshalPubKeyPoint(p.E)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm:        algo,
+		SubjectPublicKey: asn1.BitString{Bytes: e, BitLength: 8 * len(e)},
+	})
+}
+
+func (p *PubKey) MarshalPKIXPEM() ([]byte, error) {
+	der, err := p.MarshalPKIX()
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+func (p *PrivKey) marshalSEC1(oid asn1.ObjectIdentifier) ([]byte, error) {
+	if big.NewInt(1).Cmp(p.D) == 1 || p.D.Cmp(p.Curve.N) >= 0 { // d < 1 || d >= curve.N
+		return nil, errors.New("invalid privkey value")
+	}
+
+	// Fixed length of ceiling(log2(n) / 8) octets (RFC 5915 section 3)
+	d := make([]byte, (p.Curve.N.BitLen()+7)/8)
+	p.D.FillBytes(d)
+
+	e, err := marshalPubKeyPoint(p.CalcPubKey().E)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(ecPrivateKey{
+		Version:       1,
+		PrivateKey:    d,
+		NamedCurveOID: oid,
+		PublicKey:     asn1.BitString{Bytes: e, BitLength: 8 * len(e)},
+	})
+}
+
+// Curves built by hand (rather than taken from the registry) are matched by their parameters
+func curveOID(c *Curve) (asn1.ObjectIdentifier, error) {
+	if c.OID != nil {
+		return c.OID, nil
+	}
+
+	for _, d := range Curves() {
+		if d.OID != nil && c.Equals(d) {
+			return d.OID, nil
+		}
+	}
+	return nil, errors.New("curve without oid")
+}
+
+func algorithmIdentifier(oid asn1.ObjectIdentifier) (pkix.AlgorithmIdentifier, error) {
+	params, err := asn1.Marshal(oid)
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, err
+	}
+
+	return pkix.AlgorithmIdentifier{
+		Algorithm:  oidPublicKeyECDSA,
+		Parameters: asn1.RawValue{FullBytes: params},
+	}, nil
+}
+
+func marshalPubKeyPoint(e *Point) ([]byte, error) {
+	if e.AtInf || !e.OnCurve() {
+		return nil, errors.New("pubkey not on curve")
+	}
+	return e.MarshalUncompressed(), nil
+}
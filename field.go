@@ -0,0 +1,224 @@
+package ecdsa_tools
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+)
+
+// Supports moduli of up to 576 bits (eg secp521r1)
+const maxLimbs = 9
+
+// Fixed-width arithmetic modulo an odd m in the Montgomery domain (x is represented by x * R mod m, R = 2^(64 * limbs)).
+// Elements are little-endian uint64 limbs, the operations run in time independent of the element values.
+type field struct {
+	m     []uint64
+	mInv  uint64   // -m^-1 mod 2^64
+	one   []uint64 // R mod m, ie 1 in the Montgomery domain
+	r2    []uint64 // R^2 mod m, for conversion into the Montgomery domain
+	mBig  *big.Int
+	limbs int
+}
+
+func newField(m *big.Int) (*field, error) {
+	if m.Bit(0) == 0 || m.Cmp(big.NewInt(1)) != 1 {
+		return nil, errors.New("modulus not odd")
+	}
+
+	limbs := (m.BitLen() + 63) / 64
+	if limbs > maxLimbs {
+		return nil, errors.New("modulus too large")
+	}
+
+	f := &field{m: toLimbs(m, limbs), mBig: new(big.Int).Set(m), limbs: limbs}
+
+	// Newton iteration for m^-1 mod 2^64, each iteration doubles the number of correct bits
+	inv := uint64(1)
+	for i := 0; i < 6; i++ {
+		inv *= 2 - f.m[0]*inv
+	}
+	f.mInv = -inv
+
+	r := new(big.Int).Lsh(big.NewInt(1), uint(64*limbs))
+	f.one = toLimbs(new(big.Int).Mod(r, m), limbs)
+	f.r2 = toLimbs(new(big.Int).Mod(new(big.Int).Mul(r, r), m), limbs)
+
+	return f, nil
+}
+
+func (f *field) element() []uint64 {
+	return make([]uint64, f.limbs)
+}
+
+// The value should be in the range [0, m) to avoid leaking its size through the reduction
+func (f *field) toMontgomery(x *big.Int) []uint64 {
+	if x.Sign() == -1 || x.Cmp(f.mBig) >= 0 {
+		x = new(big.Int).Mod(x, f.mBig)
+	}
+
+	z := toLimbs(x, f.limbs)
+	f.mul(z, z, f.r2)
+	return z
+}
+
+func (f *field) fromMontgomery(x []uint64) *big.Int {
+	var one [maxLimbs]uint64
+	one[0] = 1
+
+	z := f.element()
+	f.mul(z, x, one[:f.limbs])
+	return fromLimbs(z)
+}
+
+// z = x + y mod m
+func (f *field) add(z, x, y []uint64) {
+	var sum, reduced [maxLimbs]uint64
+
+	var carry uint64
+	for i := 0; i < f.limbs; i++ {
+		sum[i], carry = bits.Add64(x[i], y[i], carry)
+	}
+
+	var borrow uint64
+	for i := 0; i < f.limbs; i++ {
+		reduced[i], borrow = bits.Sub64(sum[i], f.m[i], borrow)
+	}
+
+	// The sum is less than m (keep it unreduced) only if the subtraction borrowed past the carry
+	_, borrow = bits.Sub64(carry, 0, borrow)
+	ctSelect(z, reduced[:f.limbs], sum[:f.limbs], borrow)
+}
+
+// z = x - y mod m
+func (f *field) sub(z, x, y []uint64) {
+	var diff [maxLimbs]uint64
+
+	var borrow uint64
+	for i := 0; i < f.limbs; i++ {
+		diff[i], borrow = bits.Sub64(x[i], y[i], borrow)
+	}
+
+	// Add m back if the subtraction went negative
+	mask := -borrow
+	var carry uint64
+	for i := 0; i < f.limbs; i++ {
+		z[i], carry = bits.Add64(diff[i], f.m[i]&mask, carry)
+	}
+}
+
+// z = x * y * R^-1 mod m (Montgomery multiplication, coarsely integrated operand scanning)
+func (f *field) mul(z, x, y []uint64) {
+	var t [maxLimbs + 2]uint64
+	n := f.limbs
+
+	for i := 0; i < n; i++ {
+		var c uint64
+		for j := 0; j < n; j++ {
+			c, t[j] = madd(x[j], y[i], t[j], c)
+		}
+		t[n], c = bits.Add64(t[n], c, 0)
+		t[n+1] = c
+
+		m := t[0] * f.mInv
+		c, _ = madd(m, f.m[0], t[0], 0)
+		for j := 1; j < n; j++ {
+			c, t[j-1] = madd(m, f.m[j], t[j], c)
+		}
+		t[n-1], c = bits.Add64(t[n], c, 0)
+		t[n] = t[n+1] + c
+	}
+
+	// The result is less than 2m, subtract m unless that borrows
+	var reduced [maxLimbs]uint64
+	var borrow uint64
+	for i := 0; i < n; i++ {
+		reduced[i], borrow = bits.Sub64(t[i], f.m[i], borrow)
+	}
+	_, borrow = bits.Sub64(t[n], 0, borrow)
+	ctSelect(z, reduced[:n], t[:n], borrow)
+}
+
+func (f *field) square(z, x []uint64) {
+	f.mul(z, x, x)
+}
+
+// z = x^-1 mod m (via Fermat's little theorem, m must be prime), zero maps to zero
+func (f *field) inv(z, x []uint64) {
+	// The exponent is public so branching on its bits is fine
+	e := new(big.Int).Sub(f.mBig, big.NewInt(2))
+
+	var acc, base [maxLimbs]uint64
+	copy(acc[:], f.one)
+	copy(base[:], x)
+
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		f.square(acc[:f.limbs], acc[:f.limbs])
+		if e.Bit(i) == 1 {
+			f.mul(acc[:f.limbs], acc[:f.limbs], base[:f.limbs])
+		}
+	}
+
+	copy(z, acc[:f.limbs])
+}
+
+// Returns 1 if x is zero otherwise 0
+func (f *field) isZero(x []uint64) uint64 {
+	var acc uint64
+	for i := 0; i < f.limbs; i++ {
+		acc |= x[i]
+	}
+	// The top bit of (acc | -acc) is set iff acc != 0
+	return 1 ^ ((acc | -acc) >> 63)
+}
+
+// (hi, lo) = x * y + a + c
+func madd(x, y, a, c uint64) (uint64, uint64) {
+	hi, lo := bits.Mul64(x, y)
+	var carry uint64
+	lo, carry = bits.Add64(lo, a, 0)
+	hi += carry
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	return hi, lo
+}
+
+// z = y if cond == 1, z = x if cond == 0
+func ctSelect(z, x, y []uint64, cond uint64) {
+	mask := -cond
+	for i := range z {
+		z[i] = x[i] ^ (mask & (x[i] ^ y[i]))
+	}
+}
+
+// Swaps x and y if cond == 1
+func ctSwap(x, y []uint64, cond uint64) {
+	mask := -cond
+	for i := range x {
+		t := mask & (x[i] ^ y[i])
+		x[i] ^= t
+		y[i] ^= t
+	}
+}
+
+func toLimbs(x *big.Int, limbs int) []uint64 {
+	b := make([]byte, 8*limbs)
+	x.FillBytes(b)
+
+	z := make([]uint64, limbs)
+	for i := 0; i < limbs; i++ {
+		for j := 0; j < 8; j++ {
+			z[i] |= uint64(b[len(b)-1-8*i-j]) << (8 * j)
+		}
+	}
+	return z
+}
+
+func fromLimbs(x []uint64) *big.Int {
+	b := make([]byte, 8*len(x))
+	for i, w := range x {
+		for j := 0; j < 8; j++ {
+			b[len(b)-1-8*i-j] = byte(w >> (8 * j))
+		}
+	}
+	return new(big.Int).SetBytes(b)
+}
@@ -0,0 +1,66 @@
+package ecdsa_tools
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestField(t *testing.T) {
+	moduli := []*big.Int{big.NewInt(7), new(big.Int).SetUint64(0xffffffffffffffc5)} // 2^64 - 59
+	for _, curve := range curves {
+		moduli = append(moduli, curve.P, curve.N)
+	}
+
+	for _, m := range moduli {
+		f, err := newField(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		values := []*big.Int{big.NewInt(0), big.NewInt(1), new(big.Int).Sub(m, big.NewInt(1))}
+		for i := 0; i < 16; i++ {
+			v, err := rand.Int(rand.Reader, m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			values = append(values, v)
+		}
+
+		for _, x := range values {
+			for _, y := range values {
+				xm, ym := f.toMontgomery(x), f.toMontgomery(y)
+				z := f.element()
+
+				if f.fromMontgomery(xm).Cmp(x) != 0 {
+					t.Fatalf("%x: montgomery round trip mismatch", m)
+				}
+
+				f.add(z, xm, ym)
+				if expected := new(big.Int).Add(x, y); f.fromMontgomery(z).Cmp(expected.Mod(expected, m)) != 0 {
+					t.Errorf("%x: %x + %x mismatch", m, x, y)
+				}
+
+				f.sub(z, xm, ym)
+				if expected := new(big.Int).Sub(x, y); f.fromMontgomery(z).Cmp(expected.Mod(expected, m)) != 0 {
+					t.Errorf("%x: %x - %x mismatch", m, x, y)
+				}
+
+				f.mul(z, xm, ym)
+				if expected := new(big.Int).Mul(x, y); f.fromMontgomery(z).Cmp(expected.Mod(expected, m)) != 0 {
+					t.Errorf("%x: %x * %x mismatch", m, x, y)
+				}
+			}
+
+			if x.Sign() == 0 {
+				continue
+			}
+
+			z := f.toMontgomery(x)
+			f.inv(z, z)
+			if expected := new(big.Int).ModInverse(x, m); f.fromMontgomery(z).Cmp(expected) != 0 {
+				t.Errorf("%x: %x^-1 mismatch", m, x)
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package ecdsa_tools
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Fixed-base multiplication of the generator using precomputed tables.
+//
+// With w-bit windows the scalar k is written as sum(k_i * 2^(w * i)) for digits k_i in [0, 2^w),
+// so k * G = sum(k_i * (2^(w * i) * G)). Precomputing j * 2^(w * i) * G for every window i and digit j
+// leaves one table lookup and addition per window, with no doublings at all.
+// The lookups scan the entire row for a window so that the memory accesses do not depend on the digit.
+
+const generatorWindow = 4
+
+func (c *ctCurve) generatorTableFor(curve *Curve) [][]*projectivePoint {
+	c.generatorOnce.Do(func() {
+		windows := (curve.N.BitLen() + generatorWindow - 1) / generatorWindow
+
+		base := c.fromAffine(&Point{X: curve.Gx, Y: curve.Gy, Curve: curve})
+
+		table := make([][]*projectivePoint, windows)
+		for i := range table {
+			// Row i is j * 2^(w * i) * G for j in [0, 2^w)
+			row := make([]*projectivePoint, 1<<generatorWindow)
+			row[0] = c.infinity()
+			for j := 1; j < len(row); j++ {
+				row[j] = c.newPoint()
+				c.add(row[j], row[j-1], base)
+			}
+			table[i] = row
+
+			// The base for the next row is 2^w times the current one
+			for j := 0; j < generatorWindow; j++ {
+				c.add(base, base, base)
+			}
+		}
+
+		c.generatorTable = table
+	})
+
+	return c.generatorTable
+}
+
+// Computes k * G in constant time with respect to k using tables built on first use for each registered curve,
+// unregistered curves fall back to MultiplyConstantTime as building the table costs more than a multiplication
+func (c *Curve) MultiplyGenerator(k *big.Int) *Point {
+	return mustPoint(c.TryMultiplyGenerator(k))
+}
+
+func (c *Curve) TryMultiplyGenerator(k *big.Int) (*Point, error) {
+	if !c.registered() {
+		return (&Point{X: c.Gx, Y: c.Gy, Curve: c}).TryMultiplyConstantTime(k)
+	}
+
+	if k.Sign() == -1 || k.Cmp(c.N) >= 0 {
+		k = new(big.Int).Mod(k, c.N)
+	}
+
+	ct, err := ctCurveFor(c)
+	if err != nil {
+		return nil, err
+	}
+	table := ct.generatorTableFor(c)
+
+	limbs := (len(table)*generatorWindow + 63) / 64
+	kLimbs := toLimbs(k, limbs)
+
+	acc, entry := ct.infinity(), ct.newPoint()
+	for i, row := range table {
+		bit := i * generatorWindow
+		digit := (kLimbs[bit/64] >> (bit % 64)) & (1<<generatorWindow - 1)
+
+		for j, p := range row {
+			// cond is 1 iff digit == j
+			cond := uint64(j) ^ digit
+			cond = 1 ^ ((cond | -cond) >> 63)
+
+			ctSelect(entry.X, entry.X, p.X, cond)
+			ctSelect(entry.Y, entry.Y, p.Y, cond)
+			ctSelect(entry.Z, entry.Z, p.Z, cond)
+		}
+
+		ct.add(acc, acc, entry)
+	}
+
+	q := ct.toAffine(acc, c)
+
+	if !q.AtInf && !q.OnCurve() {
+		return nil, fmt.Errorf("multiplied %w", ErrNotOnCurve)
+	}
+
+	return q, nil
+}
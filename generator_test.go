@@ -0,0 +1,78 @@
+package ecdsa_tools
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func TestMultiplyGenerator(t *testing.T) {
+	for name, curve := range curves {
+		g := &Point{X: curve.Gx, Y: curve.Gy, Curve: curve}
+
+		scalars := []*big.Int{
+			big.NewInt(1),
+			big.NewInt(15),
+			big.NewInt(16),
+			new(big.Int).Sub(curve.N, big.NewInt(1)),
+			new(big.Int).Add(curve.N, big.NewInt(3)),
+		}
+		for i := 0; i < 8; i++ {
+			k, err := rand.Int(rand.Reader, curve.N)
+			if err != nil {
+				t.Fatal(err)
+			}
+			scalars = append(scalars, k)
+		}
+
+		// Build the tables concurrently
+		var wg sync.WaitGroup
+		for _, k := range scalars {
+			wg.Add(1)
+			go func(k *big.Int) {
+				defer wg.Done()
+				if !curve.MultiplyGenerator(k).Equals(g.Multiply(new(big.Int).Mod(k, curve.N))) {
+					t.Errorf("%s: k * g mismatch for k = %x", name, k)
+				}
+			}(k)
+		}
+		wg.Wait()
+	}
+}
+
+func BenchmarkMultiplyGenerator(b *testing.B) {
+	curve := curves["secp256k1"]
+	g := &Point{X: curve.Gx, Y: curve.Gy, Curve: curve}
+	k := new(big.Int).Sub(curve.N, big.NewInt(2))
+
+	// Exclude building the tables
+	curve.MultiplyGenerator(k)
+	b.ResetTimer()
+
+	b.Run("ladder", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g.MultiplyConstantTime(k)
+		}
+	})
+
+	b.Run("table", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			curve.MultiplyGenerator(k)
+		}
+	})
+}
+
+func TestMultiplyGeneratorUnregistered(t *testing.T) {
+	// An equal but unregistered copy is neither cached nor given tables
+	curve := *curves["secp256k1"]
+	g := &Point{X: curve.Gx, Y: curve.Gy, Curve: &curve}
+
+	k := big.NewInt(0xdeadbeef)
+	if !curve.MultiplyGenerator(k).Equals(g.Multiply(k)) {
+		t.Error("k * g mismatch")
+	}
+	if _, ok := ctCurves.Load(&curve); ok {
+		t.Error("unregistered curve cached")
+	}
+}
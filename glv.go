@@ -0,0 +1,67 @@
+package ecdsa_tools
+
+import (
+	"math/big"
+)
+
+// Gallant-Lambert-Vanstone (GLV) scalar decomposition.
+//
+// When p = 1 mod 3 and a = 0 (eg secp256k1) the map (x, y) -> (beta * x, y) for a cube root of unity beta mod p
+// is an endomorphism equal to multiplication by some lambda (a cube root of unity mod n). Any scalar k can be split
+// into k1 + k2 * lambda (mod n) with k1 and k2 about half the bit length of n, so k * P = k1 * P + k2 * phi(P)
+// halves the number of doublings when both are computed simultaneously (see straus).
+// See https://www.iacr.org/archive/crypto2001/21390189.pdf and libsecp256k1.
+
+type glvParams struct {
+	beta   *big.Int // Cube root of unity mod p
+	lambda *big.Int // Cube root of unity mod n, phi(P) = lambda * P
+
+	// Short basis (a1, b1), (a2, b2) of the lattice {(x, y) : x + y * lambda = 0 mod n}
+	a1, b1, a2, b2 *big.Int
+}
+
+// Returns k1, k2 such that k = k1 + k2 * lambda (mod n)
+func (g *glvParams) split(k, n *big.Int) (*big.Int, *big.Int) {
+	// Round (x / n) to the nearest integer
+	roundDiv := func(x *big.Int) *big.Int {
+		v := new(big.Int).Lsh(x, 1)
+		v.Add(v, n)
+		return v.Div(v, new(big.Int).Lsh(n, 1))
+	}
+
+	// c1 = round(b2 * k / n), c2 = round(-b1 * k / n)
+	c1 := roundDiv(new(big.Int).Mul(g.b2, k))
+	c2 := roundDiv(new(big.Int).Mul(new(big.Int).Neg(g.b1), k))
+
+	// k1 = k - c1 * a1 - c2 * a2
+	k1 := new(big.Int).Sub(k, new(big.Int).Mul(c1, g.a1))
+	k1.Sub(k1, new(big.Int).Mul(c2, g.a2))
+
+	// k2 = -c1 * b1 - c2 * b2
+	k2 := new(big.Int).Mul(c1, g.b1)
+	k2.Neg(k2)
+	k2.Sub(k2, new(big.Int).Mul(c2, g.b2))
+
+	return k1, k2
+}
+
+func (g *glvParams) endomorphism(p *jacobianPoint) *jacobianPoint {
+	// x = X / Z^2 so scaling X by beta scales x by beta
+	x := new(big.Int).Mul(p.X, g.beta)
+	x.Mod(x, p.Curve.P)
+	return &jacobianPoint{X: x, Y: new(big.Int).Set(p.Y), Z: new(big.Int).Set(p.Z), Curve: p.Curve}
+}
+
+// Replaces each (point, scalar) pair with two pairs of half-length scalars
+func (g *glvParams) expand(points []*jacobianPoint, scalars []*big.Int, n *big.Int) ([]*jacobianPoint, []*big.Int) {
+	var rvPoints []*jacobianPoint
+	var rvScalars []*big.Int
+
+	for i, p := range points {
+		k1, k2 := g.split(new(big.Int).Mod(scalars[i], n), n)
+		rvPoints = append(rvPoints, p, g.endomorphism(p))
+		rvScalars = append(rvScalars, k1, k2)
+	}
+
+	return rvPoints, rvScalars
+}
@@ -0,0 +1,72 @@
+package ecdsa_tools
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestGLV(t *testing.T) {
+	curve := curves["secp256k1"]
+	glv := curve.glv
+	n := curve.N
+	g := &Point{X: curve.Gx, Y: curve.Gy, Curve: curve}
+
+	// beta and lambda are non-trivial cube roots of unity
+	for _, v := range []struct{ x, m *big.Int }{{glv.beta, curve.P}, {glv.lambda, n}} {
+		if v.x.Cmp(big.NewInt(1)) == 0 || new(big.Int).Exp(v.x, big.NewInt(3), v.m).Cmp(big.NewInt(1)) != 0 {
+			t.Errorf("%x not a cube root of unity", v.x)
+		}
+	}
+
+	// phi(G) = lambda * G
+	if !glv.endomorphism(newJacobianPoint(g)).toAffine().Equals(newJacobianPoint(g).multiply(glv.lambda).toAffine()) {
+		t.Errorf("phi(g) != lambda * g")
+	}
+
+	// a_i + b_i * lambda = 0 (mod n)
+	for _, v := range [][2]*big.Int{{glv.a1, glv.b1}, {glv.a2, glv.b2}} {
+		w := new(big.Int).Mul(v[1], glv.lambda)
+		w.Add(w, v[0])
+		if w.Mod(w, n).Sign() != 0 {
+			t.Errorf("basis vector not in lattice")
+		}
+	}
+
+	for i := 0; i < 64; i++ {
+		k, _ := rand.Int(rand.Reader, n)
+		k1, k2 := glv.split(k, n)
+
+		v := new(big.Int).Mul(k2, glv.lambda)
+		v.Add(v, k1)
+		if v.Mod(v, n).Cmp(k) != 0 {
+			t.Errorf("k1 + k2 * lambda != k for k = %x", k)
+		}
+
+		if k1.BitLen() > 129 || k2.BitLen() > 129 {
+			t.Errorf("split of %x not half length", k)
+		}
+
+		if !g.Multiply(k).Equals(newJacobianPoint(g).multiply(k).toAffine()) {
+			t.Errorf("k * g mismatch for k = %x", k)
+		}
+	}
+}
+
+func BenchmarkGLV(b *testing.B) {
+	curve := curves["secp256k1"]
+	g := &Point{X: curve.Gx, Y: curve.Gy, Curve: curve}
+	k := newBigInt("0xd9a4b9a99984eadea545b42efe7cd1eb101d2e55b30d35eb7a79fc216c087c57")
+
+	b.Run("double-and-add", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			newJacobianPoint(g).multiply(k).toAffine()
+		}
+	})
+
+	b.Run("glv", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g.Multiply(k)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package ecdsa_tools
+
+import (
+	"math/big"
+)
+
+// Jacobian coordinates (X, Y, Z) represent the affine point (X/Z^2, Y/Z^3).
+// Addition and doubling need no modular inverse, only the final conversion back to affine does.
+// Z = 0 represents the point at infinity.
+type jacobianPoint struct {
+	X, Y, Z *big.Int
+	Curve   *Curve
+}
+
+func newJacobianPoint(p *Point) *jacobianPoint {
+	if p.AtInf {
+		return newJacobianInf(p.Curve)
+	}
+
+	return &jacobianPoint{
+		X:     new(big.Int).Mod(p.X, p.Curve.P),
+		Y:     new(big.Int).Mod(p.Y, p.Curve.P),
+		Z:     big.NewInt(1),
+		Curve: p.Curve,
+	}
+}
+
+func newJacobianInf(curve *Curve) *jacobianPoint {
+	return &jacobianPoint{X: big.NewInt(1), Y: big.NewInt(1), Z: big.NewInt(0), Curve: curve}
+}
+
+func (p *jacobianPoint) atInf() bool {
+	return p.Z.Sign() == 0
+}
+
+func (p *jacobianPoint) toAffine() *Point {
+	if p.atInf() {
+		return &Point{AtInf: true, Curve: p.Curve}
+	}
+
+	P := p.Curve.P
+
+	zInv := new(big.Int).ModInverse(p.Z, P)
+	zInv2 := new(big.Int).Mul(zInv, zInv)
+	zInv2.Mod(zInv2, P)
+	zInv3 := new(big.Int).Mul(zInv2, zInv)
+	zInv3.Mod(zInv3, P)
+
+	x := new(big.Int).Mul(p.X, zInv2)
+	x.Mod(x, P)
+	y := new(big.Int).Mul(p.Y, zInv3)
+	y.Mod(y, P)
+
+	return &Point{X: x, Y: y, Curve: p.Curve}
+}
+
+func (p *jacobianPoint) negate() *jacobianPoint {
+	y := new(big.Int).Neg(p.Y)
+	y.Mod(y, p.Curve.P)
+	return &jacobianPoint{X: new(big.Int).Set(p.X), Y: y, Z: new(big.Int).Set(p.Z), Curve: p.Curve}
+}
+
+// https://hyperelliptic.org/EFD/g1p/auto-shortw-jacobian.html#doubling-dbl-1998-cmo-2
+func (p *jacobianPoint) double() *jacobianPoint {
+	if p.atInf() || p.Y.Sign() == 0 {
+		return newJacobianInf(p.Curve)
+	}
+
+	P := p.Curve.P
+	mod := func(v *big.Int) *big.Int { return v.Mod(v, P) }
+
+	yy := mod(new(big.Int).Mul(p.Y, p.Y))
+	yyyy := mod(new(big.Int).Mul(yy, yy))
+
+	// S = 4 * X * Y^2
+	s := mod(new(big.Int).Mul(p.X, yy))
+	s = mod(s.Lsh(s, 2))
+
+	// M = 3 * X^2 + a * Z^4
+	m := mod(new(big.Int).Mul(p.X, p.X))
+	m.Mul(m, big.NewInt(3))
+	if p.Curve.A.Sign() != 0 {
+		zz := mod(new(big.Int).Mul(p.Z, p.Z))
+		zzzz := mod(zz.Mul(zz, zz))
+		m.Add(m, zzzz.Mul(zzzz, p.Curve.A))
+	}
+	mod(m)
+
+	// X3 = M^2 - 2 * S
+	x := mod(new(big.Int).Mul(m, m))
+	x.Sub(x, new(big.Int).Lsh(s, 1))
+	mod(x)
+
+	// Y3 = M * (S - X3) - 8 * Y^4
+	y := new(big.Int).Sub(s, x)
+	y = mod(y.Mul(y, m))
+	y.Sub(y, new(big.Int).Lsh(yyyy, 3))
+	mod(y)
+
+	// Z3 = 2 * Y * Z
+	z := mod(new(big.Int).Mul(p.Y, p.Z))
+	mod(z.Lsh(z, 1))
+
+	return &jacobianPoint{X: x, Y: y, Z: z, Curve: p.Curve}
+}
+
+// https://hyperelliptic.org/EFD/g1p/auto-shortw-jacobian.html#addition-add-1998-cmo-2
+func (p *jacobianPoint) add(q *jacobianPoint) *jacobianPoint {
+	if p.atInf() {
+		return q.copy()
+	}
+	if q.atInf() {
+		return p.copy()
+	}
+
+	P := p.Curve.P
+	mod := func(v *big.Int) *big.Int { return v.Mod(v, P) }
+
+	z1z1 := mod(new(big.Int).Mul(p.Z, p.Z))
+	z2z2 := mod(new(big.Int).Mul(q.Z, q.Z))
+
+	u1 := mod(new(big.Int).Mul(p.X, z2z2))
+	u2 := mod(new(big.Int).Mul(q.X, z1z1))
+
+	s1 := mod(new(big.Int).Mul(p.Y, q.Z))
+	mod(s1.Mul(s1, z2z2))
+	s2 := mod(new(big.Int).Mul(q.Y, p.Z))
+	mod(s2.Mul(s2, z1z1))
+
+	h := mod(new(big.Int).Sub(u2, u1))
+	r := mod(new(big.Int).Sub(s2, s1))
+
+	if h.Sign() == 0 {
+		if r.Sign() == 0 {
+			return p.double()
+		}
+		// Negations of each other
+		return newJacobianInf(p.Curve)
+	}
+
+	hh := mod(new(big.Int).Mul(h, h))
+	hhh := mod(new(big.Int).Mul(h, hh))
+	v := mod(new(big.Int).Mul(u1, hh))
+
+	// X3 = R^2 - H^3 - 2 * U1 * H^2
+	x := mod(new(big.Int).Mul(r, r))
+	x.Sub(x, hhh)
+	x.Sub(x, new(big.Int).Lsh(v, 1))
+	mod(x)
+
+	// Y3 = R * (U1 * H^2 - X3) - S1 * H^3
+	y := new(big.Int).Sub(v, x)
+	mod(y.Mul(y, r))
+	y.Sub(y, mod(s1.Mul(s1, hhh)))
+	mod(y)
+
+	// Z3 = Z1 * Z2 * H
+	z := mod(new(big.Int).Mul(p.Z, q.Z))
+	mod(z.Mul(z, h))
+
+	return &jacobianPoint{X: x, Y: y, Z: z, Curve: p.Curve}
+}
+
+func (p *jacobianPoint) copy() *jacobianPoint {
+	return &jacobianPoint{
+		X:     new(big.Int).Set(p.X),
+		Y:     new(big.Int).Set(p.Y),
+		Z:     new(big.Int).Set(p.Z),
+		Curve: p.Curve,
+	}
+}
+
+// Left-to-right double-and-add, not constant time
+func (p *jacobianPoint) multiply(k *big.Int) *jacobianPoint {
+	if k.Sign() == -1 {
+		return p.negate().multiply(new(big.Int).Neg(k))
+	}
+
+	q := newJacobianInf(p.Curve)
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		q = q.double()
+		if k.Bit(i) == 1 {
+			q = q.add(p)
+		}
+	}
+
+	return q
+}
@@ -0,0 +1,144 @@
+package lattice
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	ecdsa "github.com/jo-makar/ecdsa-tools"
+)
+
+// ECDSA key recovery from partially known nonces as a Hidden Number Problem (Boneh and Venkatesan,
+// Howgrave-Graham and Smart https://doi.org/10.1023/A:1011214926272, Nguyen and Shparlinski).
+//
+// Each signature gives s * k = z + r * d (mod n), ie k = t * d + u with t = r / s and u = z / s (mod n).
+// Known bits of k leave an unknown part b with 0 <= b < 2^(L - l) (L being the bit length of n, l the bits known)
+// - Most significant bits a: k = a * 2^(L - l) + b, so b = t * d + u - a * 2^(L - l) (mod n)
+// - Least significant bits a: k = b * 2^l + a, so b = (t * d + u - a) / 2^l (mod n)
+// A nonce biased to have its top l bits zero is the former with a = 0.
+//
+// Then b_i - 2^(L - l_i - 1) = t_i * d + u_i (mod n) with |b_i - 2^(L - l_i - 1)| <= 2^(L - l_i - 1), so with columns
+// scaled by 2^l_i the lattice spanned by the rows
+//   n * 2^l_1        0    ...  0    0
+//   ...
+//   0         ...    n * 2^l_m     0    0
+//   t_1 * 2^l_1 ...  t_m * 2^l_m   1    0
+//   u_1 * 2^l_1 ...  u_m * 2^l_m   0    2^(L - 1)
+// contains the unusually short vector ((b_1 - 2^(L - l_1 - 1)) * 2^l_1, ..., d, 2^(L - 1)), found by LLL
+// once the total number of known bits comfortably exceeds L.
+
+type LeakKind int
+
+const (
+	LeakMSB LeakKind = iota // The most significant bits of k (as an L bit value) are known
+	LeakLSB                 // The least significant bits of k are known
+)
+
+// A signature with the hash of the message signed and the known bits of its nonce
+type LeakedSignature struct {
+	R, S  *big.Int
+	Hash  []byte
+	Kind  LeakKind
+	Bits  int      // Number of bits of k known
+	Value *big.Int // The known bits, eg zero for nonces biased to have leading zero bits
+}
+
+const lllDelta = 0.99
+
+// Recovers the privkey from signatures with partially known nonces, checked against the pubkey
+func RecoverKey(pubkey *ecdsa.PubKey, sigs []LeakedSignature) (*ecdsa.PrivKey, error) {
+	n := pubkey.Curve.N
+	bitLen := n.BitLen()
+	m := len(sigs)
+
+	basis := make([][]*big.Int, m+2)
+	for i := range basis {
+		basis[i] = make([]*big.Int, m+2)
+		for j := range basis[i] {
+			basis[i][j] = new(big.Int)
+		}
+	}
+	embedding := new(big.Int).Lsh(big.NewInt(1), uint(bitLen-1))
+	basis[m][m].SetInt64(1)
+	basis[m+1][m+1].Set(embedding)
+
+	for i, sig := range sigs {
+		t, u, err := hnpCoefficients(sig, n)
+		if err != nil {
+			return nil, fmt.Errorf("signature %d: %w", i, err)
+		}
+
+		shift := uint(sig.Bits)
+		basis[i][i].Lsh(n, shift)
+		basis[m][i].Lsh(t, shift)
+		basis[m+1][i].Lsh(u, shift)
+	}
+
+	if err := LLL(basis, lllDelta); err != nil {
+		return nil, err
+	}
+
+	// The target vector (or its negation) with d in the second last position
+	for _, row := range basis {
+		if new(big.Int).Abs(row[m+1]).Cmp(embedding) != 0 {
+			continue
+		}
+
+		d := new(big.Int).Set(row[m])
+		if row[m+1].Sign() < 0 {
+			d.Neg(d)
+		}
+		d.Mod(d, n)
+
+		if d.Sign() != 0 && pubkey.Curve.MultiplyGenerator(d).Equals(pubkey.E) {
+			return &ecdsa.PrivKey{D: d, Curve: pubkey.Curve}, nil
+		}
+	}
+
+	return nil, errors.New("privkey not found, more signatures or known bits needed")
+}
+
+// Returns t and the centered u with b - 2^(L - l - 1) = t * d + u (mod n)
+func hnpCoefficients(sig LeakedSignature, n *big.Int) (*big.Int, *big.Int, error) {
+	bitLen := n.BitLen()
+
+	if sig.R == nil || sig.S == nil || sig.Value == nil {
+		return nil, nil, errors.New("incomplete signature")
+	}
+	for _, v := range []*big.Int{sig.R, sig.S} {
+		if v.Sign() != 1 || v.Cmp(n) != -1 {
+			return nil, nil, errors.New("signature values out of range")
+		}
+	}
+	if sig.Bits <= 0 || sig.Bits >= bitLen {
+		return nil, nil, errors.New("known bits out of range")
+	}
+	if sig.Value.Sign() < 0 || sig.Value.BitLen() > sig.Bits {
+		return nil, nil, errors.New("known value exceeds the known bits")
+	}
+
+	// z is the leftmost L bits of the hash, as when signing
+	z := new(big.Int).SetBytes(sig.Hash)
+	if len(sig.Hash)*8 > bitLen {
+		z.Rsh(z, uint(len(sig.Hash)*8-bitLen))
+	}
+
+	sInv := new(big.Int).ModInverse(sig.S, n)
+	t := new(big.Int).Mul(sig.R, sInv)
+	u := new(big.Int).Mul(z, sInv)
+
+	switch sig.Kind {
+	case LeakMSB:
+		u.Sub(u, new(big.Int).Lsh(sig.Value, uint(bitLen-sig.Bits)))
+	case LeakLSB:
+		u.Sub(u, sig.Value)
+		inv := new(big.Int).ModInverse(new(big.Int).Lsh(big.NewInt(1), uint(sig.Bits)), n)
+		t.Mul(t, inv)
+		u.Mul(u, inv)
+	default:
+		return nil, nil, errors.New("unknown leak kind")
+	}
+
+	u.Sub(u, new(big.Int).Lsh(big.NewInt(1), uint(bitLen-sig.Bits-1)))
+	return t.Mod(t, n), u.Mod(u, n), nil
+}
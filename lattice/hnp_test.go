@@ -0,0 +1,91 @@
+package lattice
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"testing"
+
+	ecdsa "github.com/jo-makar/ecdsa-tools"
+)
+
+// Signatures with nonces leaking the given bits via PrivKey.SignWithNonce
+func leakySignatures(t *testing.T, privkey *ecdsa.PrivKey, count int, kind LeakKind, bits int, biased bool) []LeakedSignature {
+	n := privkey.Curve.N
+	hashFunc := func(data []byte) []byte {
+		rv := sha256.Sum256(data)
+		return rv[:]
+	}
+
+	var sigs []LeakedSignature
+	for i := 0; i < count; i++ {
+		var k *big.Int
+		nonce := func() *big.Int {
+			var err error
+			for k == nil || k.Sign() == 0 {
+				max := n
+				if biased {
+					// The top bits zero
+					max = new(big.Int).Lsh(big.NewInt(1), uint(n.BitLen()-bits))
+				}
+				if k, err = rand.Int(rand.Reader, max); err != nil {
+					t.Fatal(err)
+				}
+			}
+			return k
+		}
+
+		msg := []byte(fmt.Sprintf("message %d", i))
+		sig, err := privkey.SignWithNonce(msg, hashFunc, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		value := new(big.Int)
+		if kind == LeakMSB {
+			value.Rsh(k, uint(n.BitLen()-bits))
+		} else {
+			value.Mod(k, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+		}
+		sigs = append(sigs, LeakedSignature{R: sig.R, S: sig.S, Hash: hashFunc(msg), Kind: kind, Bits: bits, Value: value})
+	}
+	return sigs
+}
+
+func TestRecoverKey(t *testing.T) {
+	table := []struct {
+		curve  string
+		kind   LeakKind
+		bits   int
+		count  int
+		biased bool
+	}{
+		{"secp256k1", LeakMSB, 32, 12, false},
+		{"secp256k1", LeakLSB, 32, 12, false},
+		{"prime256v1", LeakMSB, 24, 16, true},
+		{"secp384r1", LeakLSB, 48, 12, false},
+	}
+
+	for _, entry := range table {
+		privkey, err := ecdsa.NewRandomPrivKeyViaOpenSSL(entry.curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubkey := privkey.CalcPubKey()
+
+		sigs := leakySignatures(t, privkey, entry.count, entry.kind, entry.bits, entry.biased)
+		actual, err := RecoverKey(pubkey, sigs)
+		if err != nil {
+			t.Fatalf("%s: %v", entry.curve, err)
+		}
+		if actual.D.Cmp(privkey.D) != 0 {
+			t.Errorf("%s: unexpected privkey", entry.curve)
+		}
+
+		// Too few known bits in total
+		if _, err := RecoverKey(pubkey, sigs[:entry.count/3]); err == nil {
+			t.Errorf("%s: expected failure", entry.curve)
+		}
+	}
+}
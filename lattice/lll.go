@@ -0,0 +1,188 @@
+package lattice
+
+import (
+	"errors"
+	"math/big"
+)
+
+// Lenstra-Lenstra-Lovasz lattice basis reduction (https://en.wikipedia.org/wiki/Lenstra%E2%80%93Lenstra%E2%80%93Lov%C3%A1sz_lattice_basis_reduction_algorithm).
+//
+// With b*_i the Gram-Schmidt orthogonalization of the basis b_i and mu_ij = <b_i, b*_j> / <b*_j, b*_j>
+// a basis is reduced when it is
+// - Size reduced: |mu_ij| <= 1/2 for j < i, by subtracting round(mu_ij) * b_j from b_i
+// - Satisfies the Lovasz condition: |b*_k|^2 >= (delta - mu_k,k-1^2) * |b*_k-1|^2, otherwise b_k and b_k-1 are swapped
+// The first vector is then within a factor of (4 / (4 * delta - 1))^((d - 1) / 2) of the shortest,
+// much closer in practice. The basis is kept exactly (with big.Int) while the Gram-Schmidt values use big.Float
+// recomputed from the exact Gram matrix per Schnorr and Euchner.
+
+// Reduces the basis (the rows, linearly independent) in place, delta in (1/4, 1) with 0.99 typical
+func LLL(basis [][]*big.Int, delta float64) error {
+	d := len(basis)
+	if d == 0 {
+		return nil
+	}
+	if delta <= 0.25 || delta >= 1 {
+		return errors.New("delta not in (1/4, 1)")
+	}
+
+	maxBits := 0
+	for _, row := range basis {
+		if len(row) != len(basis[0]) {
+			return errors.New("basis vectors of differing lengths")
+		}
+		for _, v := range row {
+			maxBits = max(maxBits, v.BitLen())
+		}
+	}
+
+	l := &lll{
+		basis: basis,
+		gram:  make([][]*big.Int, d),
+		r:     make([][]*big.Float, d),
+		mu:    make([][]*big.Float, d),
+		prec:  uint(2*maxBits + 2*d + 64),
+	}
+	for i := range basis {
+		l.gram[i] = make([]*big.Int, d)
+		for j := 0; j <= i; j++ {
+			l.gram[i][j] = dot(basis[i], basis[j])
+			l.gram[j][i] = l.gram[i][j]
+		}
+		l.r[i] = make([]*big.Float, d)
+		l.mu[i] = make([]*big.Float, d)
+	}
+
+	deltaF := l.float().SetFloat64(delta)
+
+	if err := l.computeRow(0); err != nil {
+		return err
+	}
+	for k := 1; k < d; {
+		for {
+			if err := l.computeRow(k); err != nil {
+				return err
+			}
+			if !l.sizeReduce(k) {
+				break
+			}
+		}
+
+		// Lovasz condition, |b*_k|^2 + mu_k,k-1^2 * |b*_k-1|^2 being |b*_k + mu_k,k-1 * b*_k-1|^2
+		lhs := l.float().Mul(deltaF, l.r[k-1][k-1])
+		rhs := l.float().Mul(l.mu[k][k-1], l.mu[k][k-1])
+		rhs.Mul(rhs, l.r[k-1][k-1]).Add(rhs, l.r[k][k])
+		if lhs.Cmp(rhs) <= 0 {
+			k++
+			continue
+		}
+
+		l.swap(k)
+		if k > 1 {
+			k--
+		} else if err := l.computeRow(0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type lll struct {
+	basis [][]*big.Int
+	gram  [][]*big.Int   // Exact inner products <b_i, b_j>
+	r     [][]*big.Float // r_ij = <b_i, b*_j> for j <= i (so r_ii = |b*_i|^2)
+	mu    [][]*big.Float // mu_ij = r_ij / r_jj for j < i
+	prec  uint
+}
+
+func (l *lll) float() *big.Float {
+	return new(big.Float).SetPrec(l.prec)
+}
+
+// Gram-Schmidt values of row k, assumes those of the previous rows are current
+func (l *lll) computeRow(k int) error {
+	t := l.float()
+	for j := 0; j <= k; j++ {
+		v := l.float().SetInt(l.gram[k][j])
+		for i := 0; i < j; i++ {
+			v.Sub(v, t.Mul(l.mu[j][i], l.r[k][i]))
+		}
+		l.r[k][j] = v
+		if j < k {
+			l.mu[k][j] = l.float().Quo(v, l.r[j][j])
+		}
+	}
+
+	if l.r[k][k].Sign() <= 0 {
+		return errors.New("basis vectors linearly dependent")
+	}
+	return nil
+}
+
+// Returns whether b_k changed, the Gram-Schmidt values are then recomputed to limit the accumulated error
+func (l *lll) sizeReduce(k int) bool {
+	half := l.float().SetFloat64(0.5)
+	changed := false
+
+	for j := k - 1; j >= 0; j-- {
+		if l.float().Abs(l.mu[k][j]).Cmp(half) <= 0 {
+			continue
+		}
+
+		// q = round(mu_kj)
+		qf := l.float().Abs(l.mu[k][j])
+		qf.Add(qf, half)
+		q, _ := qf.Int(nil)
+		if l.mu[k][j].Sign() < 0 {
+			q.Neg(q)
+		}
+
+		l.subtractRow(k, j, q)
+		qf.SetInt(q)
+		t := l.float()
+		for i := 0; i < j; i++ {
+			l.mu[k][i].Sub(l.mu[k][i], t.Mul(qf, l.mu[j][i]))
+		}
+		l.mu[k][j].Sub(l.mu[k][j], qf)
+		changed = true
+	}
+
+	return changed
+}
+
+// b_k -= q * b_j, updating the Gram matrix
+func (l *lll) subtractRow(k, j int, q *big.Int) {
+	t := new(big.Int)
+	for i, v := range l.basis[j] {
+		l.basis[k][i].Sub(l.basis[k][i], t.Mul(q, v))
+	}
+
+	// <b_k - q * b_j, b_k - q * b_j> = <b_k, b_k> - 2q * <b_k, b_j> + q^2 * <b_j, b_j>
+	kk := l.gram[k][k]
+	kk.Sub(kk, t.Mul(q, l.gram[k][j]).Lsh(t, 1))
+	kk.Add(kk, t.Mul(q, q).Mul(t, l.gram[j][j]))
+
+	for i := range l.gram {
+		if i != k {
+			v := new(big.Int).Sub(l.gram[k][i], t.Mul(q, l.gram[j][i]))
+			l.gram[k][i], l.gram[i][k] = v, v
+		}
+	}
+}
+
+// Swaps b_k and b_k-1
+func (l *lll) swap(k int) {
+	l.basis[k], l.basis[k-1] = l.basis[k-1], l.basis[k]
+	l.gram[k], l.gram[k-1] = l.gram[k-1], l.gram[k]
+	for _, row := range l.gram {
+		row[k], row[k-1] = row[k-1], row[k]
+	}
+}
+
+func dot(a, b []*big.Int) *big.Int {
+	rv, t := new(big.Int), new(big.Int)
+	for i := range a {
+		rv.Add(rv, t.Mul(a[i], b[i]))
+	}
+	return rv
+}
@@ -0,0 +1,108 @@
+package lattice
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func newBasis(rows [][]int64) [][]*big.Int {
+	basis := make([][]*big.Int, len(rows))
+	for i, row := range rows {
+		basis[i] = make([]*big.Int, len(row))
+		for j, v := range row {
+			basis[i][j] = big.NewInt(v)
+		}
+	}
+	return basis
+}
+
+// Checks the reduction conditions with exact Gram-Schmidt values
+func checkReduced(t *testing.T, basis [][]*big.Int, delta float64) {
+	d := len(basis)
+	bStar := make([][]*big.Rat, d)
+	norms := make([]*big.Rat, d)
+	mu := make([][]*big.Rat, d)
+
+	dotRat := func(a, b []*big.Rat) *big.Rat {
+		rv := new(big.Rat)
+		for i := range a {
+			rv.Add(rv, new(big.Rat).Mul(a[i], b[i]))
+		}
+		return rv
+	}
+
+	for i, row := range basis {
+		bStar[i] = make([]*big.Rat, len(row))
+		for k, v := range row {
+			bStar[i][k] = new(big.Rat).SetInt(v)
+		}
+		b := make([]*big.Rat, len(row))
+		copy(b, bStar[i])
+
+		mu[i] = make([]*big.Rat, i)
+		for j := 0; j < i; j++ {
+			mu[i][j] = new(big.Rat).Quo(dotRat(b, bStar[j]), norms[j])
+			for k := range bStar[i] {
+				bStar[i][k] = new(big.Rat).Sub(bStar[i][k], new(big.Rat).Mul(mu[i][j], bStar[j][k]))
+			}
+		}
+		norms[i] = dotRat(bStar[i], bStar[i])
+	}
+
+	half := big.NewRat(1, 2)
+	for i := range mu {
+		for j := range mu[i] {
+			if new(big.Rat).Abs(mu[i][j]).Cmp(half) > 0 {
+				t.Errorf("mu_%d,%d = %s not size reduced", i, j, mu[i][j].FloatString(3))
+			}
+		}
+	}
+
+	deltaRat := new(big.Rat).SetFloat64(delta)
+	for k := 1; k < d; k++ {
+		rhs := new(big.Rat).Sub(deltaRat, new(big.Rat).Mul(mu[k][k-1], mu[k][k-1]))
+		rhs.Mul(rhs, norms[k-1])
+		if norms[k].Cmp(rhs) < 0 {
+			t.Errorf("lovasz condition fails at %d", k)
+		}
+	}
+}
+
+func TestLLL(t *testing.T) {
+	// Example from https://en.wikipedia.org/wiki/Lenstra%E2%80%93Lenstra%E2%80%93Lov%C3%A1sz_lattice_basis_reduction_algorithm
+	basis := newBasis([][]int64{{1, 1, 1}, {-1, 0, 2}, {3, 5, 6}})
+	if err := LLL(basis, 0.75); err != nil {
+		t.Fatal(err)
+	}
+	expected := newBasis([][]int64{{0, 1, 0}, {1, 0, 1}, {-1, 0, 2}})
+	for i := range basis {
+		for j := range basis[i] {
+			if basis[i][j].Cmp(expected[i][j]) != 0 {
+				t.Fatalf("unexpected basis %v", basis)
+			}
+		}
+	}
+
+	// Random bases of large entries
+	r := rand.New(rand.NewSource(1))
+	for _, d := range []int{2, 5, 10, 20} {
+		basis := make([][]*big.Int, d)
+		for i := range basis {
+			basis[i] = make([]*big.Int, d)
+			for j := range basis[i] {
+				basis[i][j] = new(big.Int).Rand(r, new(big.Int).Lsh(big.NewInt(1), 200))
+				basis[i][j].Sub(basis[i][j], new(big.Int).Lsh(big.NewInt(1), 199))
+			}
+		}
+
+		if err := LLL(basis, 0.99); err != nil {
+			t.Fatal(err)
+		}
+		checkReduced(t, basis, 0.99)
+	}
+
+	if err := LLL(newBasis([][]int64{{1, 2}, {2, 4}}), 0.99); err == nil {
+		t.Error("expected linearly dependent error")
+	}
+}
@@ -0,0 +1,120 @@
+package ecdsa_tools
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Nonce reuse: two signatures with the same k (hence the same r) over different hashes z1 and z2 give
+//   s1 - s2 = k^-1 * (z1 + r * d) - k^-1 * (z2 + r * d) = k^-1 * (z1 - z2) (mod n)
+// so k = (z1 - z2) / (s1 - s2) and d = (s1 * k - z1) / r (mod n).
+// Since (r, -s) is also a valid signature (with -k) both s2 and -s2 are tried.
+
+// A signature with the hash of the message signed, eg from a signing log
+type SignedHash struct {
+	R, S *big.Int
+	Hash []byte
+}
+
+type NonceReuse struct {
+	First, Second int // Indices of a pair of signatures sharing r
+	K             *big.Int
+	PrivKey       *PrivKey
+}
+
+// Finds signatures sharing r and recovers the nonce and privkey from each r shared by signatures over different
+// hashes, the recovered privkey is checked against the pubkey. Repeated signatures of the same hash are ignored.
+// Pairs that fail (eg signed by another key) are skipped, an r for which no pair succeeds is reported in the
+// (joined) error returned along with the reuses recovered from the others.
+func (p *PubKey) RecoverNonceReuse(sigs []SignedHash) ([]*NonceReuse, error) {
+	var rv []*NonceReuse
+	var errs []error
+
+	byR := make(map[string][]int)
+	var order []string
+	for i, sig := range sigs {
+		if sig.R == nil || sig.S == nil {
+			return nil, fmt.Errorf("signature %d incomplete", i)
+		}
+
+		key := string(sig.R.Bytes())
+		if _, ok := byR[key]; !ok {
+			order = append(order, key)
+		}
+		byR[key] = append(byR[key], i)
+	}
+
+	for _, key := range order {
+		indices := byR[key]
+
+		// A single pair with different hashes (mod n) suffices for each r
+		var found bool
+		var pairErrs []error
+		for a := 0; a < len(indices) && !found; a++ {
+			for b := a + 1; b < len(indices) && !found; b++ {
+				i, j := indices[a], indices[b]
+
+				n := p.Curve.N
+				z1 := new(big.Int).Mod(hashToInt(sigs[i].Hash, n), n)
+				z2 := new(big.Int).Mod(hashToInt(sigs[j].Hash, n), n)
+				if z1.Cmp(z2) == 0 {
+					continue
+				}
+
+				k, d, err := p.recoverFromPair(sigs[i], sigs[j])
+				if err != nil {
+					pairErrs = append(pairErrs, fmt.Errorf("signatures %d and %d: %w", i, j, err))
+					continue
+				}
+				rv = append(rv, &NonceReuse{First: i, Second: j, K: k, PrivKey: &PrivKey{D: d, Curve: p.Curve}})
+				found = true
+			}
+		}
+		if !found {
+			errs = append(errs, pairErrs...)
+		}
+	}
+
+	return rv, errors.Join(errs...)
+}
+
+func (p *PubKey) recoverFromPair(sig1, sig2 SignedHash) (*big.Int, *big.Int, error) {
+	n := p.Curve.N
+
+	for _, v := range []*big.Int{sig1.R, sig1.S, sig2.S} {
+		if v.Sign() != 1 || v.Cmp(n) != -1 {
+			return nil, nil, errors.New("signature values out of range")
+		}
+	}
+
+	z1, z2 := hashToInt(sig1.Hash, n), hashToInt(sig2.Hash, n)
+	dz := new(big.Int).Sub(z1, z2)
+
+	rInv := new(big.Int).ModInverse(sig1.R, n)
+	if rInv == nil {
+		return nil, nil, errors.New("r not invertible")
+	}
+
+	for _, s2 := range []*big.Int{sig2.S, new(big.Int).Sub(n, sig2.S)} {
+		ds := new(big.Int).Sub(sig1.S, s2)
+		dsInv := new(big.Int).ModInverse(ds.Mod(ds, n), n)
+		if dsInv == nil {
+			continue
+		}
+
+		k := new(big.Int).Mul(dz, dsInv)
+		k.Mod(k, n)
+
+		d := new(big.Int).Mul(sig1.S, k)
+		d.Sub(d, z1)
+		d.Mul(d, rInv)
+		d.Mod(d, n)
+
+		if d.Sign() != 0 && p.Curve.MultiplyGenerator(d).Equals(p.E) {
+			return k, d, nil
+		}
+	}
+
+	return nil, nil, errors.New("recovered privkey does not match pubkey")
+}
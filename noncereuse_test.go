@@ -0,0 +1,102 @@
+package ecdsa_tools
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+func TestRecoverNonceReuse(t *testing.T) {
+	for _, curve := range []string{"secp256k1", "prime256v1", "secp521r1"} {
+		privkey, err := NewRandomPrivKeyViaOpenSSL(curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubkey := privkey.CalcPubKey()
+
+		hash := func(msg string) []byte {
+			rv := sha256.Sum256([]byte(msg))
+			return rv[:]
+		}
+		signWithK := func(msg string, k int64) SignedHash {
+			h := hash(msg)
+			sig, err := privkey.sign(h, func() (*big.Int, error) { return big.NewInt(k), nil })
+			if err != nil {
+				t.Fatal(err)
+			}
+			return SignedHash{R: sig.R, S: sig.S, Hash: h}
+		}
+
+		sigs := []SignedHash{
+			signWithK("first", 0xdeadbeef),
+			signWithK("first", 0xcafe),
+			signWithK("first", 0xdeadbeef), // Repeated, does not reveal anything
+			signWithK("second", 0xdeadbeef),
+			signWithK("third", 0xcafe),
+		}
+
+		// (r, -s) is equally valid, as produced by low-s normalization
+		sigs[4].S = new(big.Int).Sub(privkey.Curve.N, sigs[4].S)
+
+		reuses, err := pubkey.RecoverNonceReuse(sigs)
+		if err != nil {
+			t.Fatalf("%s: %v", curve, err)
+		}
+		if len(reuses) != 2 {
+			t.Fatalf("%s: expected two reuses, got %d", curve, len(reuses))
+		}
+
+		expected := []struct {
+			first, second int
+			k             int64
+		}{{0, 3, 0xdeadbeef}, {1, 4, 0xcafe}}
+		for i, entry := range expected {
+			reuse := reuses[i]
+			if reuse.First != entry.first || reuse.Second != entry.second {
+				t.Errorf("%s: unexpected pair (%d, %d)", curve, reuse.First, reuse.Second)
+			}
+			if reuse.K.Cmp(big.NewInt(entry.k)) != 0 {
+				t.Errorf("%s: unexpected k %x", curve, reuse.K)
+			}
+			if reuse.PrivKey.D.Cmp(privkey.D) != 0 {
+				t.Errorf("%s: unexpected privkey", curve)
+			}
+		}
+
+		// A pair that fails does not prevent recovering the others
+		bogus := append(sigs[:len(sigs):len(sigs)],
+			SignedHash{R: big.NewInt(12345), S: big.NewInt(1), Hash: hash("fourth")},
+			SignedHash{R: big.NewInt(12345), S: big.NewInt(2), Hash: hash("fifth")},
+		)
+		if reuses, err := pubkey.RecoverNonceReuse(bogus); err == nil || len(reuses) != 2 {
+			t.Errorf("%s: unexpected result with bogus pair: %d, %v", curve, len(reuses), err)
+		}
+
+		// Hashes equal mod n sign the same value
+		if n := privkey.Curve.N; n.BitLen()%8 == 0 {
+			h1, h2 := make([]byte, n.BitLen()/8), make([]byte, n.BitLen()/8)
+			big.NewInt(5).FillBytes(h1)
+			new(big.Int).Add(n, big.NewInt(5)).FillBytes(h2)
+			var same []SignedHash
+			for _, h := range [][]byte{h1, h2} {
+				sig, err := privkey.sign(h, func() (*big.Int, error) { return big.NewInt(7), nil })
+				if err != nil {
+					t.Fatal(err)
+				}
+				same = append(same, SignedHash{R: sig.R, S: sig.S, Hash: h})
+			}
+			if reuses, err := pubkey.RecoverNonceReuse(same); err != nil || len(reuses) != 0 {
+				t.Errorf("%s: unexpected result for hashes equal mod n: %d, %v", curve, len(reuses), err)
+			}
+		}
+
+		// Signatures from another key sharing r
+		other, err := NewRandomPrivKeyViaOpenSSL(curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := other.CalcPubKey().RecoverNonceReuse(sigs); err == nil {
+			t.Errorf("%s: expected pubkey mismatch", curve)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package ecdsa_tools
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Multi-scalar multiplication sum(k_i * P_i) via Pippenger's bucket method.
+//
+// The scalars are split into c-bit windows. For each window every point is added to the bucket
+// selected by its digit, then the buckets are combined as sum(j * B_j) using running sums
+// (B_m + (B_m + B_m-1) + ...) so that no multiplications are needed. The windows are combined from
+// the most significant with c doublings in between. Not constant time, for use with public scalars only.
+
+func MultiScalarMult(points []*Point, scalars []*big.Int) *Point {
+	return mustPoint(TryMultiScalarMult(points, scalars))
+}
+
+func TryMultiScalarMult(points []*Point, scalars []*big.Int) (*Point, error) {
+	if len(points) != len(scalars) {
+		return nil, errors.New("points scalars length mismatch")
+	}
+	if len(points) == 0 {
+		return nil, errors.New("no points")
+	}
+
+	curve := points[0].Curve
+
+	jpoints := make([]*jacobianPoint, len(points))
+	ks := make([]*big.Int, len(scalars))
+	maxBits := 0
+
+	for i, p := range points {
+		if !p.Curve.Equals(curve) {
+			return nil, ErrCurveMismatch
+		}
+		if !p.AtInf && !p.OnCurve() {
+			return nil, fmt.Errorf("point %d %w", i, ErrNotOnCurve)
+		}
+
+		jpoints[i] = newJacobianPoint(p)
+		ks[i] = scalars[i]
+		if ks[i].Sign() == -1 {
+			jpoints[i] = jpoints[i].negate()
+			ks[i] = new(big.Int).Neg(ks[i])
+		}
+
+		if l := ks[i].BitLen(); l > maxBits {
+			maxBits = l
+		}
+	}
+
+	c := pippengerWindow(len(points))
+	buckets := make([]*jacobianPoint, 1<<c)
+
+	acc := newJacobianInf(curve)
+	for start := ((maxBits - 1) / c) * c; start >= 0; start -= c {
+		for j := 0; j < c; j++ {
+			acc = acc.double()
+		}
+
+		for j := range buckets {
+			buckets[j] = nil
+		}
+
+		for i, k := range ks {
+			digit := 0
+			for b := c - 1; b >= 0; b-- {
+				digit = digit<<1 | int(k.Bit(start+b))
+			}
+			if digit == 0 {
+				continue
+			}
+
+			if buckets[digit] == nil {
+				buckets[digit] = jpoints[i]
+			} else {
+				buckets[digit] = buckets[digit].add(jpoints[i])
+			}
+		}
+
+		// sum(j * B_j) = B_m + (B_m + B_m-1) + ... + (B_m + ... + B_1)
+		running, sum := newJacobianInf(curve), newJacobianInf(curve)
+		for j := len(buckets) - 1; j >= 1; j-- {
+			if buckets[j] != nil {
+				running = running.add(buckets[j])
+			}
+			sum = sum.add(running)
+		}
+
+		acc = acc.add(sum)
+	}
+
+	r := acc.toAffine()
+	if !r.AtInf && !r.OnCurve() {
+		return nil, fmt.Errorf("multiplied %w", ErrNotOnCurve)
+	}
+
+	return r, nil
+}
+
+// Roughly minimizes the cost of n additions per window plus 2^c additions to combine the buckets
+func pippengerWindow(n int) int {
+	switch {
+	case n < 4:
+		return 2
+	case n < 32:
+		return 3
+	}
+
+	c := 1
+	for 1<<(c+1) < n {
+		c++
+	}
+
+	// ie about ln(n) for large n
+	c = c * 69 / 100
+	if c < 4 {
+		c = 4
+	}
+	if c > 16 {
+		c = 16
+	}
+	return c
+}
@@ -0,0 +1,71 @@
+package ecdsa_tools
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestMultiScalarMult(t *testing.T) {
+	curve := curves["prime256v1"]
+	g := &Point{X: curve.Gx, Y: curve.Gy, Curve: curve}
+
+	for _, n := range []int{1, 2, 5, 40, 120} {
+		points := make([]*Point, n)
+		scalars := make([]*big.Int, n)
+
+		expected := &Point{AtInf: true, Curve: curve}
+		for i := range points {
+			d, _ := rand.Int(rand.Reader, curve.N)
+			k, _ := rand.Int(rand.Reader, curve.N)
+			if i%7 == 3 {
+				k.Neg(k)
+			}
+
+			points[i] = g.Multiply(d.Add(d, big.NewInt(1)))
+			scalars[i] = k
+
+			if k.Sign() != 0 {
+				expected = expected.Add(points[i].Multiply(k))
+			}
+		}
+
+		if !MultiScalarMult(points, scalars).Equals(expected) {
+			t.Errorf("%d points: sum(k_i * p_i) mismatch", n)
+		}
+	}
+
+	// Terms cancelling out
+	p := g.Multiply(big.NewInt(5))
+	if !MultiScalarMult([]*Point{g, p}, []*big.Int{big.NewInt(10), big.NewInt(-2)}).AtInf {
+		t.Errorf("expected point at infinity")
+	}
+}
+
+func BenchmarkMultiScalarMult(b *testing.B) {
+	curve := curves["secp256k1"]
+	g := &Point{X: curve.Gx, Y: curve.Gy, Curve: curve}
+
+	points := make([]*Point, 256)
+	scalars := make([]*big.Int, len(points))
+	for i := range points {
+		d, _ := rand.Int(rand.Reader, curve.N)
+		points[i] = g.Multiply(d.Add(d, big.NewInt(1)))
+		scalars[i], _ = rand.Int(rand.Reader, curve.N)
+	}
+
+	b.Run("naive", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			acc := &Point{AtInf: true, Curve: curve}
+			for j, p := range points {
+				acc = acc.Add(p.Multiply(scalars[j]))
+			}
+		}
+	})
+
+	b.Run("pippenger", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			MultiScalarMult(points, scalars)
+		}
+	})
+}
@@ -2,8 +2,8 @@ package ecdsa_tools
 
 import (
 	"errors"
+	"fmt"
 	"math/big"
-	"slices"
 )
 
 type Point struct {
@@ -12,16 +12,32 @@ type Point struct {
 	Curve *Curve
 }
 
+var (
+	ErrCurveMismatch   = errors.New("points not on same curve")
+	ErrPointAtInfinity = errors.New("point at infinity")
+)
+
 func NewPoint(x, y *big.Int, curve *Curve) (*Point, error) {
 	p := &Point{X: x, Y: y, Curve: curve}
 
 	if !p.OnCurve() {
-		return nil, errors.New("point not on curve")
+		return nil, ErrNotOnCurve
 	}
 
 	return p, nil
 }
 
+// Returns ErrPointAtInfinity or ErrNotOnCurve unless p is a (finite) point on its curve
+func (p *Point) Validate() error {
+	if p.AtInf {
+		return ErrPointAtInfinity
+	}
+	if !p.OnCurve() {
+		return ErrNotOnCurve
+	}
+	return nil
+}
+
 func (p *Point) OnCurve() bool {
 	if p.AtInf {
 		return false
@@ -63,12 +79,24 @@ func (p *Point) Equals(q *Point) bool {
 }
 
 func (p *Point) IsNegation(q *Point) bool {
+	rv, err := p.TryIsNegation(q)
+	if err != nil {
+		panic(err)
+	}
+	return rv
+}
+
+func (p *Point) TryIsNegation(q *Point) (bool, error) {
 	if !p.Curve.Equals(q.Curve) {
-		panic(errors.New("points not on same curve"))
+		return false, ErrCurveMismatch
+	}
+
+	if p.AtInf || q.AtInf {
+		return p.AtInf && q.AtInf, nil
 	}
 
 	if p.X.Cmp(q.X) != 0 {
-		return false
+		return false, nil
 	}
 
 	signs := p.Y.Sign() + q.Y.Sign()
@@ -76,16 +104,22 @@ func (p *Point) IsNegation(q *Point) bool {
 		// Both signs are the same
 		rY := new(big.Int).Neg(p.Y)
 		rY.Add(rY, p.Curve.P)
-		return q.Y.Cmp(rY) == 0
+		return q.Y.Cmp(rY) == 0, nil
 	} else {
-		return p.Y.Cmp(new(big.Int).Neg(q.Y)) == 0
+		return p.Y.Cmp(new(big.Int).Neg(q.Y)) == 0, nil
 	}
 }
 
+// The arithmetic below panics where the Try variants return an error
+
 func (p *Point) Negate() *Point {
+	return mustPoint(p.TryNegate())
+}
+
+func (p *Point) TryNegate() (*Point, error) {
 	// TODO This wasn't explicit in references but seems correct / natural
 	if p.AtInf {
-		return &Point{AtInf: true, Curve: p.Curve}
+		return &Point{AtInf: true, Curve: p.Curve}, nil
 	}
 
 	q := &Point{
@@ -95,36 +129,40 @@ func (p *Point) Negate() *Point {
 	}
 
 	if !q.OnCurve() {
-		panic(errors.New("negated point not on curve"))
+		return nil, fmt.Errorf("negated %w", ErrNotOnCurve)
 	}
 
-	return q
+	return q, nil
 }
 
 func (p *Point) Add(q *Point) *Point {
+	return mustPoint(p.TryAdd(q))
+}
+
+func (p *Point) TryAdd(q *Point) (*Point, error) {
 	if !p.Curve.Equals(q.Curve) {
-		panic(errors.New("points not on same curve"))
+		return nil, ErrCurveMismatch
 	}
 
 	if p.AtInf && q.AtInf {
-		return &Point{AtInf: true, Curve: p.Curve}
+		return &Point{AtInf: true, Curve: p.Curve}, nil
 	} else if p.AtInf && !q.AtInf {
-		return &Point{X: new(big.Int).Set(q.X), Y: new(big.Int).Set(q.Y), Curve: p.Curve}
+		return &Point{X: new(big.Int).Set(q.X), Y: new(big.Int).Set(q.Y), Curve: p.Curve}, nil
 	} else if !p.AtInf && q.AtInf {
-		return &Point{X: new(big.Int).Set(p.X), Y: new(big.Int).Set(p.Y), Curve: p.Curve}
+		return &Point{X: new(big.Int).Set(p.X), Y: new(big.Int).Set(p.Y), Curve: p.Curve}, nil
 	}
 
 	if p.Equals(q) {
-		return p.Double()
+		return p.TryDouble()
 	}
 
 	if p.IsNegation(q) {
-		return &Point{AtInf: true, Curve: p.Curve}
+		return &Point{AtInf: true, Curve: p.Curve}, nil
 	}
 
-	// This should not be possible (ie if p.x == q.x then p.y == -q.y)
+	// Only possible for points not on the curve (otherwise if p.x == q.x then p.y == -q.y)
 	if p.X.Cmp(q.X) == 0 {
-		panic(errors.New("points with same x but not negations"))
+		return nil, fmt.Errorf("same x but not negations, %w", ErrNotOnCurve)
 	}
 
 	lambda := new(big.Int)
@@ -147,15 +185,20 @@ func (p *Point) Add(q *Point) *Point {
 	r := &Point{X: x, Y: y, Curve: p.Curve}
 
 	if !r.OnCurve() {
-		panic(errors.New("added point not on curve"))
+		return nil, fmt.Errorf("added %w", ErrNotOnCurve)
 	}
 
-	return r
+	return r, nil
 }
 
 func (p *Point) Double() *Point {
-	if p.AtInf {
-		return &Point{AtInf: true, Curve: p.Curve}
+	return mustPoint(p.TryDouble())
+}
+
+func (p *Point) TryDouble() (*Point, error) {
+	// A point with y = 0 is its own negation
+	if p.AtInf || new(big.Int).Mod(p.Y, p.Curve.P).Sign() == 0 {
+		return &Point{AtInf: true, Curve: p.Curve}, nil
 	}
 
 	lambda := new(big.Int)
@@ -187,68 +230,44 @@ func (p *Point) Double() *Point {
 	q := &Point{X: x, Y: y, Curve: p.Curve}
 
 	if !q.OnCurve() {
-		panic(errors.New("doubled point not on curve"))
+		return nil, fmt.Errorf("doubled %w", ErrNotOnCurve)
 	}
 
-	return q
+	return q, nil
 }
 
 func (p *Point) Multiply(k *big.Int) *Point {
-	if p.AtInf || k.Cmp(big.NewInt(0)) == 0 {
-		return &Point{AtInf: true, Curve: p.Curve}
-	}
+	return mustPoint(p.TryMultiply(k))
+}
 
-	cache := map[string]*Point{
-		big.NewInt(1).String(): {
-			X:     new(big.Int).Set(p.X),
-			Y:     new(big.Int).Set(p.Y),
-			Curve: p.Curve,
-		},
+func (p *Point) TryMultiply(k *big.Int) (*Point, error) {
+	if p.AtInf || k.Cmp(big.NewInt(0)) == 0 {
+		return &Point{AtInf: true, Curve: p.Curve}, nil
 	}
-	keys := []*big.Int{big.NewInt(1)}
-
-	// Build the cache of (point) factors
-	{
-		i := big.NewInt(1)
-		q := cache[i.String()]
-		for {
-			i.Mul(i, big.NewInt(2))
-			if i.Cmp(k) == 1 { // i > k
-				break
-			}
-
-			q = q.Double()
-			cache[i.String()] = q
-			keys = append(keys, new(big.Int).Set(i))
-		}
 
-		slices.Reverse(keys)
+	var q *Point
+	if glv := p.Curve.glv; glv != nil {
+		points, scalars := glv.expand([]*jacobianPoint{newJacobianPoint(p)}, []*big.Int{k}, p.Curve.N)
+		q = straus(points, scalars).toAffine()
+	} else {
+		q = newJacobianPoint(p).multiply(k).toAffine()
 	}
 
-	// Factor k using the cache
-	i := big.NewInt(1)
-	q := cache[i.String()]
-	for {
-		for _, j := range keys {
-			if new(big.Int).Add(i, j).Cmp(k) == 1 {
-				continue
-			}
-
-			q = q.Add(cache[j.String()])
-			i.Add(i, j)
-
-			// Check for the largest factor each iteration
-			break
-		}
-
-		if i.Cmp(k) == 0 {
-			break
-		}
+	// Eg k a multiple of the order of p
+	if q.AtInf {
+		return q, nil
 	}
 
 	if !q.OnCurve() {
-		panic(errors.New("multiplied point not on curve"))
+		return nil, fmt.Errorf("multiplied %w", ErrNotOnCurve)
 	}
 
-	return q
+	return q, nil
+}
+
+func mustPoint(p *Point, err error) *Point {
+	if err != nil {
+		panic(err)
+	}
+	return p
 }
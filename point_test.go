@@ -1,7 +1,10 @@
 package ecdsa_tools
 
 import (
+	"crypto/rand"
+	"errors"
 	"math/big"
+	"slices"
 	"testing"
 )
 
@@ -44,6 +47,112 @@ func TestAdd(t *testing.T) {
 	}
 }
 
+func TestPointErrors(t *testing.T) {
+	curve := &Curve{P: big.NewInt(7), A: big.NewInt(3), B: big.NewInt(4)}
+	other := &Curve{P: big.NewInt(7), A: big.NewInt(3), B: big.NewInt(5)}
+
+	p := &Point{X: big.NewInt(1), Y: big.NewInt(1), Curve: curve}
+	offCurve := &Point{X: big.NewInt(1), Y: big.NewInt(2), Curve: curve}
+
+	if _, err := p.TryAdd(&Point{X: big.NewInt(1), Y: big.NewInt(1), Curve: other}); !errors.Is(err, ErrCurveMismatch) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := p.TryAdd(offCurve); !errors.Is(err, ErrNotOnCurve) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := offCurve.TryDouble(); !errors.Is(err, ErrNotOnCurve) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := offCurve.TryNegate(); !errors.Is(err, ErrNotOnCurve) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := NewPoint(big.NewInt(1), big.NewInt(2), curve); !errors.Is(err, ErrNotOnCurve) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// (6, 0) has order two
+	q, err := (&Point{X: big.NewInt(6), Y: big.NewInt(0), Curve: curve}).TryDouble()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Validate(); !errors.Is(err, ErrPointAtInfinity) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := offCurve.Validate(); !errors.Is(err, ErrNotOnCurve) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// The multiplications
+	mismatched := &Point{X: big.NewInt(1), Y: big.NewInt(1), Curve: other}
+	one := big.NewInt(1)
+	if _, err := p.TryIsNegation(mismatched); !errors.Is(err, ErrCurveMismatch) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := p.TryDoubleScalarMultiply(one, mismatched, one); !errors.Is(err, ErrCurveMismatch) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := p.TryDoubleScalarMultiply(big.NewInt(0), offCurve, one); !errors.Is(err, ErrNotOnCurve) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := TryMultiScalarMult([]*Point{p, mismatched}, []*big.Int{one, one}); !errors.Is(err, ErrCurveMismatch) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := TryMultiScalarMult([]*Point{offCurve}, []*big.Int{one}); !errors.Is(err, ErrNotOnCurve) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Also when multiplied by zero
+	zero := big.NewInt(0)
+	if _, err := TryMultiScalarMult([]*Point{offCurve}, []*big.Int{zero}); !errors.Is(err, ErrNotOnCurve) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := p.TryDoubleScalarMultiply(one, offCurve, zero); !errors.Is(err, ErrNotOnCurve) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := TryMultiScalarMult(nil, nil); err == nil {
+		t.Error("expected no points error")
+	}
+	if _, err := offCurve.TryMultiplyConstantTime(one); !errors.Is(err, ErrNotOnCurve) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	offCurveG := &Curve{P: curve.P, A: curve.A, B: curve.B, Gx: offCurve.X, Gy: offCurve.Y}
+	if _, err := offCurveG.TryMultiplyGenerator(one); !errors.Is(err, ErrNotOnCurve) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	evenP := &Point{X: big.NewInt(1), Y: big.NewInt(1), Curve: &Curve{P: big.NewInt(8), A: big.NewInt(0), B: big.NewInt(0)}}
+	if _, err := evenP.TryMultiplyConstantTime(one); err == nil {
+		t.Error("expected even p error")
+	}
+
+	// The panicking variants panic with the same errors
+	func() {
+		defer func() {
+			if err, ok := recover().(error); !ok || !errors.Is(err, ErrNotOnCurve) {
+				t.Errorf("unexpected panic: %v", err)
+			}
+		}()
+		offCurve.Double()
+	}()
+	func() {
+		defer func() {
+			if err, ok := recover().(error); !ok || !errors.Is(err, ErrCurveMismatch) {
+				t.Errorf("unexpected panic: %v", err)
+			}
+		}()
+		p.IsNegation(mismatched)
+	}()
+
+	// A multiple of the order is the point at infinity rather than an error
+	g := &Point{X: curves["secp256k1"].Gx, Y: curves["secp256k1"].Gy, Curve: curves["secp256k1"]}
+	if r, err := g.TryMultiply(curves["secp256k1"].N); err != nil || !r.AtInf {
+		t.Errorf("n * g != o")
+	}
+}
+
 func TestAddNegation(t *testing.T) {
 	curve := &Curve{
 		P: big.NewInt(7),
@@ -159,3 +268,120 @@ func TestMultiply(t *testing.T) {
 		t.Errorf("unexpected result")
 	}
 }
+
+func TestMultiplyJacobian(t *testing.T) {
+	toyCurve := &Curve{
+		P: big.NewInt(17),
+		A: big.NewInt(0),
+		B: big.NewInt(7),
+	}
+	toyPoint := &Point{X: big.NewInt(15), Y: big.NewInt(13), Curve: toyCurve}
+
+	// The toy point has order 18 so avoid multiples of it (resulting in the point at infinity)
+	for k := int64(1); k < 18; k++ {
+		if !toyPoint.Multiply(big.NewInt(k)).Equals(multiplyAffine(toyPoint, big.NewInt(k))) {
+			t.Errorf("toy curve: %d * p mismatch", k)
+		}
+	}
+
+	for name, curve := range curves {
+		g := &Point{X: curve.Gx, Y: curve.Gy, Curve: curve}
+
+		for i := 0; i < 8; i++ {
+			k, err := rand.Int(rand.Reader, curve.N)
+			if err != nil {
+				t.Fatal(err)
+			}
+			k.Add(k, big.NewInt(1))
+
+			if !g.Multiply(k).Equals(multiplyAffine(g, k)) {
+				t.Errorf("%s: k * g mismatch for k = %x", name, k)
+			}
+		}
+
+		// Negative scalars multiply the negated point
+		k := big.NewInt(12345)
+		if !g.Multiply(new(big.Int).Neg(k)).Equals(multiplyAffine(g, k).Negate().Multiply(big.NewInt(1))) {
+			t.Errorf("%s: -k * g mismatch", name)
+		}
+	}
+}
+
+func BenchmarkMultiply(b *testing.B) {
+	curve := curves["secp256k1"]
+	g := &Point{X: curve.Gx, Y: curve.Gy, Curve: curve}
+	k := newBigInt("0xd9a4b9a99984eadea545b42efe7cd1eb101d2e55b30d35eb7a79fc216c087c57")
+
+	b.Run("affine", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			multiplyAffine(g, k)
+		}
+	})
+
+	b.Run("jacobian", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g.Multiply(k)
+		}
+	})
+}
+
+// Original affine double-and-add implementation of Point.Multiply, kept as a reference
+func multiplyAffine(p *Point, k *big.Int) *Point {
+	if p.AtInf || k.Cmp(big.NewInt(0)) == 0 {
+		return &Point{AtInf: true, Curve: p.Curve}
+	}
+
+	cache := map[string]*Point{
+		big.NewInt(1).String(): {
+			X:     new(big.Int).Set(p.X),
+			Y:     new(big.Int).Set(p.Y),
+			Curve: p.Curve,
+		},
+	}
+	keys := []*big.Int{big.NewInt(1)}
+
+	// Build the cache of (point) factors
+	{
+		i := big.NewInt(1)
+		q := cache[i.String()]
+		for {
+			i.Mul(i, big.NewInt(2))
+			if i.Cmp(k) == 1 { // i > k
+				break
+			}
+
+			q = q.Double()
+			cache[i.String()] = q
+			keys = append(keys, new(big.Int).Set(i))
+		}
+
+		slices.Reverse(keys)
+	}
+
+	// Factor k using the cache
+	i := big.NewInt(1)
+	q := cache[i.String()]
+	for {
+		for _, j := range keys {
+			if new(big.Int).Add(i, j).Cmp(k) == 1 {
+				continue
+			}
+
+			q = q.Add(cache[j.String()])
+			i.Add(i, j)
+
+			// Check for the largest factor each iteration
+			break
+		}
+
+		if i.Cmp(k) == 0 {
+			break
+		}
+	}
+
+	if !q.OnCurve() {
+		panic(errors.New("multiplied point not on curve"))
+	}
+
+	return q
+}
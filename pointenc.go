@@ -0,0 +1,237 @@
+package ecdsa_tools
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// SEC1 (https://www.secg.org/sec1-v2.pdf) section 2.3.3 and 2.3.4 point encodings:
+// 0x00 for the point at infinity, 0x04 || x || y uncompressed, 0x02 or 0x03 (even or odd y) || x compressed
+// and 0x06 or 0x07 (even or odd y) || x || y hybrid. The coordinates are fixed length big-endian.
+
+var (
+	ErrInvalidPointLength = errors.New("invalid point encoding length")
+	ErrInvalidPointPrefix = errors.New("invalid point encoding prefix")
+	ErrInvalidCoordinate  = errors.New("point coordinate out of range")
+	ErrHybridParity       = errors.New("hybrid point encoding parity mismatch")
+	ErrNotOnCurve         = errors.New("point not on curve")
+	ErrNoSquareRoot       = errors.New("no square root exists")
+)
+
+func (p *Point) MarshalUncompressed() []byte {
+	if p.AtInf {
+		return []byte{0x00}
+	}
+
+	size := p.Curve.coordSize()
+	b := make([]byte, 1+2*size)
+	b[0] = 0x04
+	new(big.Int).Mod(p.X, p.Curve.P).FillBytes(b[1 : 1+size])
+	new(big.Int).Mod(p.Y, p.Curve.P).FillBytes(b[1+size:])
+	return b
+}
+
+func (p *Point) MarshalCompressed() []byte {
+	if p.AtInf {
+		return []byte{0x00}
+	}
+
+	size := p.Curve.coordSize()
+	b := make([]byte, 1+size)
+	b[0] = 0x02 | byte(new(big.Int).Mod(p.Y, p.Curve.P).Bit(0))
+	new(big.Int).Mod(p.X, p.Curve.P).FillBytes(b[1:])
+	return b
+}
+
+func (p *Point) MarshalHybrid() []byte {
+	b := p.MarshalUncompressed()
+	if !p.AtInf {
+		b[0] = 0x06 | (b[len(b)-1] & 1)
+	}
+	return b
+}
+
+// Decodes any of the SEC1 encodings, the errors returned can be checked with errors.Is
+func UnmarshalPoint(curve *Curve, b []byte) (*Point, error) {
+	if len(b) == 0 {
+		return nil, ErrInvalidPointLength
+	}
+
+	size := curve.coordSize()
+
+	coord := func(b []byte) (*big.Int, error) {
+		v := new(big.Int).SetBytes(b)
+		if v.Cmp(curve.P) >= 0 {
+			return nil, ErrInvalidCoordinate
+		}
+		return v, nil
+	}
+
+	switch b[0] {
+	case 0x00:
+		if len(b) != 1 {
+			return nil, ErrInvalidPointLength
+		}
+		return &Point{AtInf: true, Curve: curve}, nil
+
+	case 0x02, 0x03:
+		if len(b) != 1+size {
+			return nil, ErrInvalidPointLength
+		}
+
+		x, err := coord(b[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := curve.decompressY(x, uint(b[0]&1))
+		if err != nil {
+			return nil, err
+		}
+
+		return &Point{X: x, Y: y, Curve: curve}, nil
+
+	case 0x04, 0x06, 0x07:
+		if len(b) != 1+2*size {
+			return nil, ErrInvalidPointLength
+		}
+
+		x, err := coord(b[1 : 1+size])
+		if err != nil {
+			return nil, err
+		}
+		y, err := coord(b[1+size:])
+		if err != nil {
+			return nil, err
+		}
+
+		if b[0] != 0x04 && uint(b[0]&1) != y.Bit(0) {
+			return nil, ErrHybridParity
+		}
+
+		p := &Point{X: x, Y: y, Curve: curve}
+		if !p.OnCurve() {
+			return nil, ErrNotOnCurve
+		}
+		return p, nil
+
+	default:
+		return nil, fmt.Errorf("%w: 0x%02x", ErrInvalidPointPrefix, b[0])
+	}
+}
+
+func (c *Curve) coordSize() int {
+	return (c.P.BitLen() + 7) / 8
+}
+
+// Solves y^2 = x^3 + ax + b (mod p) for the y with the given parity
+func (c *Curve) decompressY(x *big.Int, parity uint) (*big.Int, error) {
+	rhs := new(big.Int).Exp(x, big.NewInt(3), c.P)
+	rhs.Add(rhs, new(big.Int).Mul(c.A, x))
+	rhs.Add(rhs, c.B)
+	rhs.Mod(rhs, c.P)
+
+	y, ok := modSqrt(rhs, c.P)
+	if !ok {
+		return nil, fmt.Errorf("%w: %w", ErrNotOnCurve, ErrNoSquareRoot)
+	}
+
+	if y.Bit(0) != parity {
+		if y.Sign() == 0 {
+			// The only root is zero which is even
+			return nil, ErrNotOnCurve
+		}
+		y.Sub(c.P, y)
+	}
+
+	return y, nil
+}
+
+// Square root of a modulo an odd prime p, returns false if a is a quadratic non-residue
+func modSqrt(a, p *big.Int) (*big.Int, bool) {
+	a = new(big.Int).Mod(a, p)
+	if a.Sign() == 0 {
+		return new(big.Int), true
+	}
+
+	one := big.NewInt(1)
+	pMinus1 := new(big.Int).Sub(p, one)
+
+	// Euler's criterion: a^((p - 1) / 2) = 1 iff a is a quadratic residue
+	if new(big.Int).Exp(a, new(big.Int).Rsh(pMinus1, 1), p).Cmp(one) != 0 {
+		return nil, false
+	}
+
+	var r *big.Int
+
+	switch {
+	case p.Bit(0) == 1 && p.Bit(1) == 1:
+		// p = 3 (mod 4): r = a^((p + 1) / 4)
+		e := new(big.Int).Add(p, one)
+		r = new(big.Int).Exp(a, e.Rsh(e, 2), p)
+
+	case p.Bit(0) == 1 && p.Bit(1) == 0 && p.Bit(2) == 1:
+		// p = 5 (mod 8), Atkin's algorithm: v = (2a)^((p - 5) / 8), i = 2av^2, r = av(i - 1)
+		twoA := new(big.Int).Lsh(a, 1)
+		e := new(big.Int).Sub(p, big.NewInt(5))
+		v := new(big.Int).Exp(twoA, e.Rsh(e, 3), p)
+		i := new(big.Int).Mul(v, v)
+		i.Mul(i, twoA)
+		i.Mod(i, p)
+		r = new(big.Int).Mul(a, v)
+		r.Mul(r, i.Sub(i, one))
+		r.Mod(r, p)
+
+	default:
+		r = tonelliShanks(a, p)
+	}
+
+	if new(big.Int).Exp(r, big.NewInt(2), p).Cmp(a) != 0 {
+		return nil, false
+	}
+	return r, true
+}
+
+// https://en.wikipedia.org/wiki/Tonelli%E2%80%93Shanks_algorithm, a must be a quadratic residue
+func tonelliShanks(a, p *big.Int) *big.Int {
+	one := big.NewInt(1)
+	pMinus1 := new(big.Int).Sub(p, one)
+
+	// p - 1 = q * 2^s with q odd
+	s := pMinus1.TrailingZeroBits()
+	q := new(big.Int).Rsh(pMinus1, s)
+
+	// Find a quadratic non-residue z
+	z := big.NewInt(2)
+	halfPMinus1 := new(big.Int).Rsh(pMinus1, 1)
+	for new(big.Int).Exp(z, halfPMinus1, p).Cmp(pMinus1) != 0 {
+		z.Add(z, one)
+	}
+
+	m := s
+	c := new(big.Int).Exp(z, q, p)
+	t := new(big.Int).Exp(a, q, p)
+	r := new(big.Int).Exp(a, new(big.Int).Rsh(new(big.Int).Add(q, one), 1), p)
+
+	for t.Cmp(one) != 0 {
+		// Find the least i in (0, m) such that t^(2^i) = 1
+		i := uint(0)
+		for u := new(big.Int).Set(t); u.Cmp(one) != 0; i++ {
+			u.Mul(u, u).Mod(u, p)
+		}
+
+		// b = c^(2^(m - i - 1))
+		b := new(big.Int).Set(c)
+		for j := uint(0); j < m-i-1; j++ {
+			b.Mul(b, b).Mod(b, p)
+		}
+
+		m = i
+		c.Mul(b, b).Mod(c, p)
+		t.Mul(t, c).Mod(t, p)
+		r.Mul(r, b).Mod(r, p)
+	}
+
+	return r
+}
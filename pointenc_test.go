@@ -0,0 +1,118 @@
+package ecdsa_tools
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestModSqrt(t *testing.T) {
+	// 3 mod 4, 5 mod 8 and 1 mod 8 (Tonelli-Shanks) primes
+	primes := []*big.Int{
+		big.NewInt(7),
+		big.NewInt(13),
+		big.NewInt(17),
+		big.NewInt(97),
+		newBigInt("0xffffffffffffffffffffffffffffffff000000000000000000000001"), // secp224r1 p, 1 mod 2^96
+		curves["secp256k1"].P,
+		curves["prime256v1"].P,
+	}
+
+	for _, p := range primes {
+		for i := 0; i < 32; i++ {
+			x, _ := rand.Int(rand.Reader, p)
+			a := new(big.Int).Mul(x, x)
+			a.Mod(a, p)
+
+			r, ok := modSqrt(a, p)
+			if !ok {
+				t.Fatalf("%d: no root found for %d", p, a)
+			}
+			if r.Cmp(x) != 0 && r.Cmp(new(big.Int).Sub(p, x)) != 0 {
+				t.Errorf("%d: unexpected root %d of %d", p, r, a)
+			}
+		}
+
+		// Find a non-residue via Euler's criterion
+		halfPMinus1 := new(big.Int).Rsh(new(big.Int).Sub(p, big.NewInt(1)), 1)
+		for a := big.NewInt(2); ; a.Add(a, big.NewInt(1)) {
+			if new(big.Int).Exp(a, halfPMinus1, p).Cmp(big.NewInt(1)) != 0 {
+				if _, ok := modSqrt(a, p); ok {
+					t.Errorf("%d: root found for non-residue %d", p, a)
+				}
+				break
+			}
+		}
+	}
+}
+
+func TestPointEncoding(t *testing.T) {
+	for name, curve := range curves {
+		g := &Point{X: curve.Gx, Y: curve.Gy, Curve: curve}
+
+		for i := 0; i < 8; i++ {
+			k, _ := rand.Int(rand.Reader, curve.N)
+			p := g.Multiply(k.Add(k, big.NewInt(1)))
+
+			for _, b := range [][]byte{p.MarshalUncompressed(), p.MarshalCompressed(), p.MarshalHybrid()} {
+				q, err := UnmarshalPoint(curve, b)
+				if err != nil {
+					t.Fatalf("%s: %s", name, err)
+				}
+				if !q.Equals(p) {
+					t.Errorf("%s: round trip mismatch for %x", name, b)
+				}
+			}
+
+			// A negated point differs only in the parity of y
+			if c, d := p.MarshalCompressed(), p.Negate().MarshalCompressed(); c[0] == d[0] || !bytes.Equal(c[1:], d[1:]) {
+				t.Errorf("%s: unexpected negated encoding", name)
+			}
+		}
+
+		inf := &Point{AtInf: true, Curve: curve}
+		if q, err := UnmarshalPoint(curve, inf.MarshalCompressed()); err != nil || !q.AtInf {
+			t.Errorf("%s: point at infinity round trip failed", name)
+		}
+	}
+}
+
+func TestPointEncodingErrors(t *testing.T) {
+	curve := curves["secp256k1"]
+	g := &Point{X: curve.Gx, Y: curve.Gy, Curve: curve}
+
+	uncompressed := g.MarshalUncompressed()
+	compressed := g.MarshalCompressed()
+	hybrid := g.MarshalHybrid()
+
+	modify := func(b []byte, f func([]byte)) []byte {
+		c := bytes.Clone(b)
+		f(c)
+		return c
+	}
+
+	table := []struct {
+		b   []byte
+		err error
+	}{
+		{nil, ErrInvalidPointLength},
+		{[]byte{0x00, 0x00}, ErrInvalidPointLength},
+		{uncompressed[:len(uncompressed)-1], ErrInvalidPointLength},
+		{compressed[:len(compressed)-1], ErrInvalidPointLength},
+		{modify(compressed, func(b []byte) { b[0] = 0x05 }), ErrInvalidPointPrefix},
+		{modify(uncompressed, func(b []byte) { b[len(b)-1] ^= 1 }), ErrNotOnCurve},
+		{modify(hybrid, func(b []byte) { b[0] ^= 1 }), ErrHybridParity},
+		{modify(compressed, func(b []byte) { copy(b[1:], curve.P.Bytes()) }), ErrInvalidCoordinate},
+
+		// x = 5 has no corresponding y on secp256k1 (5^3 + 7 is a non-residue)
+		{modify(compressed, func(b []byte) { big.NewInt(5).FillBytes(b[1:]) }), ErrNoSquareRoot},
+	}
+
+	for _, entry := range table {
+		if _, err := UnmarshalPoint(curve, entry.b); !errors.Is(err, entry.err) {
+			t.Errorf("%x: expected %v, got %v", entry.b, entry.err, err)
+		}
+	}
+}
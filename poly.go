@@ -0,0 +1,277 @@
+package ecdsa_tools
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// Polynomials over F_p for Schoof's algorithm, coefficients are least significant first and fully reduced.
+// The zero polynomial has no coefficients, others have a non-zero leading coefficient.
+
+type poly []*big.Int
+
+type polyField struct {
+	p *big.Int
+}
+
+func (pf *polyField) trim(a poly) poly {
+	for len(a) > 0 && a[len(a)-1].Sign() == 0 {
+		a = a[:len(a)-1]
+	}
+	return a
+}
+
+// From coefficients (least significant first) which need not be reduced
+func (pf *polyField) newPoly(coeffs ...*big.Int) poly {
+	a := make(poly, len(coeffs))
+	for i, c := range coeffs {
+		a[i] = new(big.Int).Mod(c, pf.p)
+	}
+	return pf.trim(a)
+}
+
+func (pf *polyField) degree(a poly) int {
+	return len(a) - 1
+}
+
+func (pf *polyField) equal(a, b poly) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Cmp(b[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (pf *polyField) add(a, b poly) poly {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+
+	c := make(poly, len(a))
+	for i := range a {
+		c[i] = new(big.Int).Set(a[i])
+		if i < len(b) {
+			c[i].Add(c[i], b[i])
+			if c[i].Cmp(pf.p) >= 0 {
+				c[i].Sub(c[i], pf.p)
+			}
+		}
+	}
+	return pf.trim(c)
+}
+
+func (pf *polyField) neg(a poly) poly {
+	c := make(poly, len(a))
+	for i := range a {
+		c[i] = new(big.Int)
+		if a[i].Sign() != 0 {
+			c[i].Sub(pf.p, a[i])
+		}
+	}
+	return c
+}
+
+func (pf *polyField) sub(a, b poly) poly {
+	return pf.add(a, pf.neg(b))
+}
+
+func (pf *polyField) scale(a poly, k *big.Int) poly {
+	c := make(poly, len(a))
+	for i := range a {
+		c[i] = new(big.Int).Mul(a[i], k)
+		c[i].Mod(c[i], pf.p)
+	}
+	return pf.trim(c)
+}
+
+// Kronecker substitution: the coefficients are packed into a single integer (evaluating at x = 2^(64 * slot)),
+// multiplied using the fast (Karatsuba) integer multiplication and unpacked. Each slot is wide enough
+// to hold any coefficient of the product before reduction, ie sum(a_i * b_j) < min(len(a), len(b)) * p^2.
+func (pf *polyField) mul(a, b poly) poly {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+
+	slot := (2*pf.p.BitLen() + bits.Len(uint(min(len(a), len(b)))) + 63) / 64
+
+	pack := func(v poly) *big.Int {
+		words := make([]big.Word, len(v)*slot)
+		for i, c := range v {
+			copy(words[i*slot:], c.Bits())
+		}
+		return new(big.Int).SetBits(words)
+	}
+
+	words := new(big.Int).Mul(pack(a), pack(b)).Bits()
+
+	c := make(poly, len(a)+len(b)-1)
+	for i := range c {
+		c[i] = new(big.Int)
+		if start := i * slot; start < len(words) {
+			w := make([]big.Word, slot)
+			copy(w, words[start:min(start+slot, len(words))])
+			c[i].SetBits(w).Mod(c[i], pf.p)
+		}
+	}
+	return pf.trim(c)
+}
+
+// Quotient and remainder of a / b, b must be non-zero
+func (pf *polyField) divMod(a, b poly) (poly, poly) {
+	if len(a) < len(b) {
+		return nil, a
+	}
+
+	leadInv := new(big.Int).ModInverse(b[len(b)-1], pf.p)
+
+	r := make(poly, len(a))
+	for i := range a {
+		r[i] = new(big.Int).Set(a[i])
+	}
+	q := make(poly, len(a)-len(b)+1)
+
+	// The remainder coefficients are only reduced when they become the leading coefficient (and at the end)
+	t := new(big.Int)
+	for i := len(a) - 1; i >= len(b)-1; i-- {
+		k := i - (len(b) - 1)
+		q[k] = r[i].Mod(r[i], pf.p)
+		if q[k].Sign() == 0 {
+			continue
+		}
+		if leadInv.Cmp(big.NewInt(1)) != 0 {
+			q[k].Mul(q[k], leadInv).Mod(q[k], pf.p)
+		}
+
+		for j := 0; j < len(b)-1; j++ {
+			r[k+j].Sub(r[k+j], t.Mul(q[k], b[j]))
+		}
+	}
+
+	r = r[:len(b)-1]
+	for i := range r {
+		r[i].Mod(r[i], pf.p)
+	}
+	return pf.trim(q), pf.trim(r)
+}
+
+func (pf *polyField) mod(a, h poly) poly {
+	_, r := pf.divMod(a, h)
+	return r
+}
+
+// Scales a non-zero polynomial to have a leading coefficient of one
+func (pf *polyField) monic(a poly) poly {
+	return pf.scale(a, new(big.Int).ModInverse(a[len(a)-1], pf.p))
+}
+
+func (pf *polyField) gcd(a, b poly) poly {
+	for len(b) > 0 {
+		a, b = b, pf.mod(a, b)
+	}
+	if len(a) == 0 {
+		return nil
+	}
+	return pf.monic(a)
+}
+
+// Returns the inverse of a modulo h and the (monic) gcd of a and h, the inverse is only valid if the gcd is one
+func (pf *polyField) invMod(a, h poly) (poly, poly) {
+	// Extended Euclid tracking only the coefficient of a: s * a = r (mod h)
+	r0, r1 := h, pf.mod(a, h)
+	var s0 poly
+	s1 := pf.newPoly(big.NewInt(1))
+
+	for len(r1) > 0 {
+		q, r := pf.divMod(r0, r1)
+		r0, r1 = r1, r
+		s0, s1 = s1, pf.sub(s0, pf.mul(q, s1))
+	}
+
+	if len(r0) == 0 {
+		return nil, nil
+	}
+
+	leadInv := new(big.Int).ModInverse(r0[len(r0)-1], pf.p)
+	return pf.mod(pf.scale(s0, leadInv), h), pf.scale(r0, leadInv)
+}
+
+// a mod x^k
+func (pf *polyField) truncate(a poly, k int) poly {
+	if len(a) > k {
+		a = a[:k]
+	}
+	return pf.trim(a)
+}
+
+// Coefficients in reverse order as a polynomial of the given degree
+func (pf *polyField) reverse(a poly, degree int) poly {
+	r := make(poly, degree+1)
+	for i := range r {
+		r[i] = new(big.Int)
+		if j := degree - i; j < len(a) {
+			r[i].Set(a[j])
+		}
+	}
+	return pf.trim(r)
+}
+
+// Fixed modulus h with a precomputed inverse for division via multiplication only:
+// if a = q * h + r then rev(a) = rev(q) * rev(h) (mod x^(deg a - deg h + 1)), so rev(q) = rev(a) * rev(h)^-1.
+type polyModulus struct {
+	pf     *polyField
+	h      poly
+	revInv poly // rev(h)^-1 mod x^deg(h)
+}
+
+func (pf *polyField) newModulus(h poly) *polyModulus {
+	n := pf.degree(h)
+	revH := pf.reverse(h, n)
+
+	// Newton iteration g' = g * (2 - rev(h) * g) doubling the precision each step
+	g := pf.newPoly(new(big.Int).ModInverse(revH[0], pf.p))
+	two := pf.newPoly(big.NewInt(2))
+	for prec := 1; prec < n; {
+		prec = min(2*prec, n)
+		e := pf.truncate(pf.mul(pf.truncate(revH, prec), g), prec)
+		g = pf.truncate(pf.mul(g, pf.sub(two, e)), prec)
+	}
+
+	return &polyModulus{pf: pf, h: h, revInv: g}
+}
+
+func (m *polyModulus) reduce(a poly) poly {
+	pf := m.pf
+	n := pf.degree(m.h)
+
+	k := pf.degree(a) - n + 1 // Number of quotient coefficients
+	if k <= 0 {
+		return a
+	}
+	if k > n {
+		return pf.mod(a, m.h)
+	}
+
+	revQ := pf.truncate(pf.mul(pf.truncate(pf.reverse(a, pf.degree(a)), k), m.revInv), k)
+	q := pf.reverse(revQ, k-1)
+	return pf.truncate(pf.sub(a, pf.mul(q, m.h)), n)
+}
+
+func (m *polyModulus) mul(a, b poly) poly {
+	return m.reduce(m.pf.mul(a, b))
+}
+
+func (m *polyModulus) pow(a poly, e *big.Int) poly {
+	r := m.reduce(m.pf.newPoly(big.NewInt(1)))
+	a = m.reduce(a)
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		r = m.mul(r, r)
+		if e.Bit(i) == 1 {
+			r = m.mul(r, a)
+		}
+	}
+	return r
+}
@@ -0,0 +1,47 @@
+package ecdsa_tools
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestPolyModulus(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	p := newBigInt("0xfffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f")
+	pf := &polyField{p: p}
+
+	randomPoly := func(n int) poly {
+		coeffs := make([]*big.Int, n)
+		for i := range coeffs {
+			coeffs[i] = new(big.Int).Rand(r, p)
+		}
+		return pf.newPoly(coeffs...)
+	}
+
+	for _, n := range []int{1, 2, 7, 40, 129} {
+		h := pf.monic(randomPoly(n + 1))
+		m := pf.newModulus(h)
+
+		for i := 0; i < 4; i++ {
+			a, b := randomPoly(n), randomPoly(n)
+
+			// (a * b) mod h via division and via the precomputed inverse
+			product := pf.mul(a, b)
+			q, rem := pf.divMod(product, h)
+			if !pf.equal(pf.add(pf.mul(q, h), rem), product) || pf.degree(rem) >= n {
+				t.Errorf("n = %d: divMod mismatch", n)
+			}
+			if !pf.equal(m.mul(a, b), rem) {
+				t.Errorf("n = %d: reduce mismatch", n)
+			}
+
+			// a * a^-1 = 1 (mod h) unless they share a factor
+			if inv, g := pf.invMod(a, h); pf.degree(g) == 0 {
+				if one := m.mul(a, inv); !pf.equal(one, pf.newPoly(big.NewInt(1))) {
+					t.Errorf("n = %d: inverse mismatch", n)
+				}
+			}
+		}
+	}
+}
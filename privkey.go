@@ -1,146 +1,90 @@
 package ecdsa_tools
 
 import (
+	"crypto"
+	"crypto/ecdsa"
 	"crypto/rand"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"hash"
+	"io"
 	"math/big"
+	"os"
 	"os/exec"
-	"slices"
 	"strings"
 )
 
 type PrivKey struct {
 	D     *big.Int // Private key
 	Curve *Curve
+	LowS  bool // Normalize signatures to low s (see Signature.Normalize), off unless set by the caller, eg for Bitcoin and Ethereum
 }
 
 func NewRandomPrivKeyViaOpenSSL(curve string) (*PrivKey, error) {
-	if !slices.Contains(supportedCurves, curve) {
-		return nil, fmt.Errorf("unsupported curve: %s", curve)
-	}
-
-	encodedPrivKey, err := execStdout("", "openssl", "ecparam", "-name", curve, "-genkey")
-	if err != nil {
-		return nil, err
-	}
-
-	decodedPrivKey, err := execStdout(encodedPrivKey, "openssl", "ec", "-text", "-noout")
+	c, err := CurveByName(curve)
 	if err != nil {
 		return nil, err
 	}
 
-	decodedPrivKeyLines := strings.Split(decodedPrivKey, "\n")
-
-	hexPrivKey, err := extractHexKey(decodedPrivKeyLines, "priv:")
+	encodedPrivKey, err := execStdout("", "openssl", "ecparam", "-name", curve, "-genkey")
 	if err != nil {
 		return nil, err
 	}
 
-	hexPubKey, err := extractHexKey(decodedPrivKeyLines, "pub:")
+	privkey, err := NewPrivKeyFromPEM([]byte(encodedPrivKey))
 	if err != nil {
 		return nil, err
 	}
-
-	privkey := new(big.Int)
-	if _, ok := privkey.SetString(hexPrivKey, 16); !ok {
-		return nil, errors.New("invalid hex value")
+	if !privkey.Curve.Equals(c) {
+		return nil, errors.New("unexpected curve")
 	}
 
-	if len(hexPubKey) != 130 || hexPubKey[0:2] != "04" {
-		return nil, errors.New("unexpected pubkey format")
-	}
+	return privkey, nil
+}
 
-	c := curves[curve]
-	pubkey := &Point{X: new(big.Int), Y: new(big.Int), Curve: c}
-	if _, ok := pubkey.X.SetString(hexPubKey[2:2+64], 16); !ok {
-		return nil, errors.New("invalid hex value")
-	}
-	if _, ok := pubkey.Y.SetString(hexPubKey[2+64:2+64+64], 16); !ok {
-		return nil, errors.New("invalid hex value")
+// Accepts PEM (SEC1 or PKCS#8) or DER encoded files, eg those produced by openssl ecparam -genkey
+func NewPrivKeyViaOpenSSLFile(privKeyPath string) (*PrivKey, error) {
+	data, err := os.ReadFile(privKeyPath)
+	if err != nil {
+		return nil, err
 	}
 
-	if !pubkey.OnCurve() {
-		return nil, errors.New("pubkey not on curve")
+	if block, _ := pem.Decode(data); block != nil {
+		return NewPrivKeyFromPEM(data)
 	}
 
-	// Verify pubkey = privkey * G
-	g := &Point{X: c.Gx, Y: c.Gy, Curve: c}
-	if !pubkey.Equals(g.Multiply(privkey)) {
-		return nil, errors.New("pubkey privkey mismatch")
+	if isPKCS8(data) {
+		return NewPrivKeyFromPKCS8(data)
 	}
-
-	return &PrivKey{D: privkey, Curve: c}, nil
+	return NewPrivKeyFromSEC1(data)
 }
 
-func NewPrivKeyViaOpenSSLFile(privKeyPath string) (*PrivKey, error) {
-	decodedPrivKey, err := execStdout("", "openssl", "ec", "-text", "-noout", "-in", privKeyPath)
+// Supports the curves implemented by crypto/elliptic, see Curve.StdCurve
+func NewRandomPrivKeyViaStdLib(curve string) (*PrivKey, error) {
+	c, err := CurveByName(curve)
 	if err != nil {
 		return nil, err
 	}
 
-	decodedPrivKeyLines := strings.Split(decodedPrivKey, "\n")
-
-	curve, err := extractField(decodedPrivKeyLines, "ASN1 OID:")
+	stdCurve, err := c.StdCurve()
 	if err != nil {
 		return nil, err
 	}
-	if !slices.Contains(supportedCurves, curve) {
-		return nil, fmt.Errorf("unsupported curve: %s", curve)
-	}
 
-	hexPrivKey, err := extractHexKey(decodedPrivKeyLines, "priv:")
+	k, err := ecdsa.GenerateKey(stdCurve, rand.Reader)
 	if err != nil {
 		return nil, err
 	}
+	return NewPrivKeyFromStdLib(k)
+}
 
-	hexPubKey, err := extractHexKey(decodedPrivKeyLines, "pub:")
+func NewRandomPrivKeyBitcoin() (*PrivKey, error) {
+	curve, err := CurveByName("secp256k1")
 	if err != nil {
 		return nil, err
 	}
 
-	privkey := new(big.Int)
-	if _, ok := privkey.SetString(hexPrivKey, 16); !ok {
-		return nil, errors.New("invalid hex value")
-	}
-
-	if len(hexPubKey) != 130 || hexPubKey[0:2] != "04" {
-		return nil, errors.New("unexpected pubkey format")
-	}
-
-	c := curves[curve]
-	pubkey := &Point{X: new(big.Int), Y: new(big.Int), Curve: c}
-	if _, ok := pubkey.X.SetString(hexPubKey[2:2+64], 16); !ok {
-		return nil, errors.New("invalid hex value")
-	}
-	if _, ok := pubkey.Y.SetString(hexPubKey[2+64:2+64+64], 16); !ok {
-		return nil, errors.New("invalid hex value")
-	}
-
-	if !pubkey.OnCurve() {
-		return nil, errors.New("pubkey not on curve")
-	}
-
-	// Verify pubkey = privkey * G
-	g := &Point{X: c.Gx, Y: c.Gy, Curve: c}
-	if !pubkey.Equals(g.Multiply(privkey)) {
-		return nil, errors.New("pubkey privkey mismatch")
-	}
-
-	return &PrivKey{D: privkey, Curve: c}, nil
-}
-
-func NewRandomPrivKeyViaStdLib(curve string) (*PrivKey, error) {
-	if !slices.Contains(supportedCurves, curve) {
-		return nil, fmt.Errorf("unsupported curve: %s", curve)
-	}
-
-	return nil, errors.New("TODO implement using crypto/ecdsa.GenerateKey")
-}
-
-func NewRandomPrivKeyBitcoin() (*PrivKey, error) {
-	curve := curves["secp256k1"]
-
 	d, err := rand.Int(rand.Reader, curve.N)
 	if err != nil {
 		return nil, err
@@ -165,7 +109,10 @@ func NewRandomPrivKeyBitcoin() (*PrivKey, error) {
 }
 
 func NewPrivKeyBitcoin(privKey string) (*PrivKey, error) {
-	curve := curves["secp256k1"]
+	curve, err := CurveByName("secp256k1")
+	if err != nil {
+		return nil, err
+	}
 
 	d := new(big.Int)
 	if _, ok := d.SetString(privKey, 16); !ok {
@@ -198,8 +145,7 @@ func NewPrivKeyEthereum(privKey string) (*PrivKey, error) {
 }
 
 func (p *PrivKey) CalcPubKey() *PubKey {
-	g := &Point{X: p.Curve.Gx, Y: p.Curve.Gy, Curve: p.Curve}
-	return &PubKey{E: g.Multiply(p.D), Curve: p.Curve}
+	return &PubKey{E: p.Curve.MultiplyGenerator(p.D), Curve: p.Curve}
 }
 
 func execStdout(stdin string, name string, args ...string) (string, error) {
@@ -217,54 +163,115 @@ func execStdout(stdin string, name string, args ...string) (string, error) {
 	return stdout.String(), nil
 }
 
-func extractHexKey(lines []string, prefix string) (string, error) {
-	prefixIndex := slices.Index(lines, prefix)
-	if prefixIndex == -1 {
-		return "", fmt.Errorf("prefix %s not found", prefix)
-	}
+// Signs the message hash with a random k, see also SignDeterministic and SignHedged
+func (p *PrivKey) SignMessage(msg []byte, hashFunc func([]byte) []byte) (*Signature, error) {
+	return p.SignDigest(hashFunc(msg))
+}
 
-	var hexKey strings.Builder
-	for i := prefixIndex + 1; i < len(lines) && strings.HasPrefix(lines[i], " "); i++ {
-		hexKey.WriteString(strings.TrimSpace(lines[i]))
+// Signs an already computed message hash with a random k, the leftmost n.BitLen() bits of the digest are used
+func (p *PrivKey) SignDigest(digest []byte) (*Signature, error) {
+	if err := p.Curve.checkOrder(); err != nil {
+		return nil, err
 	}
-	if hexKey.Len() == 0 {
-		return "", fmt.Errorf("no lines following prefix %s", prefix)
+	n := p.Curve.N
+
+	return p.sign(digest, func() (*big.Int, error) {
+		// Generate a random integer k in the range [1, n-1]
+		k, err := rand.Int(rand.Reader, new(big.Int).Sub(n, big.NewInt(1)))
+		if err != nil {
+			return nil, err
+		}
+		return k.Add(k, big.NewInt(1)), nil
+	})
+}
+
+// Signs the hash of everything read from r (until EOF) with a random k, eg for streaming large files
+func (p *PrivKey) SignReader(r io.Reader, h crypto.Hash) (*Signature, error) {
+	digest, err := hashReader(r, h)
+	if err != nil {
+		return nil, err
 	}
+	return p.SignDigest(digest)
+}
 
-	return strings.ReplaceAll(hexKey.String(), ":", ""), nil
+// Signs with nonces from the given function (called again whenever r or s is zero) which must return k in [1, n-1].
+// Intended for demonstrating attacks on weak nonces (see the lattice package), use SignDeterministic otherwise.
+func (p *PrivKey) SignWithNonce(msg []byte, hashFunc func([]byte) []byte, nonce func() *big.Int) (*Signature, error) {
+	if err := p.Curve.checkOrder(); err != nil {
+		return nil, err
+	}
+	return p.sign(hashFunc(msg), func() (*big.Int, error) { return nonce(), nil })
 }
 
-func extractField(lines []string, prefix string) (string, error) {
-	for _, line := range lines {
-		if strings.HasPrefix(line, prefix) {
-			return strings.TrimSpace(line[len(prefix):]), nil
-		}
+// Signs with k derived from the privkey and message hash per RFC 6979
+func (p *PrivKey) SignDeterministic(msg []byte, newHash func() hash.Hash) (*Signature, error) {
+	h := newHash()
+	h.Write(msg)
+	digest := h.Sum(nil)
+
+	if err := p.Curve.checkOrder(); err != nil {
+		return nil, err
 	}
-	return "", fmt.Errorf("prefix %s not found", prefix)
+	nonces := newRFC6979(p.D, p.Curve.N, digest, nil, newHash)
+	return p.sign(digest, func() (*big.Int, error) { return nonces.next(), nil })
 }
 
-func (p *PrivKey) Sign(msg []byte, hashFunc func([]byte) []byte) (*big.Int, *big.Int) {
-	n := p.Curve.N
-	g := &Point{X: p.Curve.Gx, Y: p.Curve.Gy, Curve: p.Curve}
+// Signs with k derived per RFC 6979 but with additional entropy mixed in (section 3.6),
+// comparable to the BIP340 aux_rand construction, crypto/rand.Reader is used when entropy is nil
+func (p *PrivKey) SignHedged(msg []byte, newHash func() hash.Hash, entropy io.Reader) (*Signature, error) {
+	h := newHash()
+	h.Write(msg)
+	return p.signHedged(h.Sum(nil), newHash, entropy)
+}
 
-	hash := hashFunc(msg)
-	h := new(big.Int).SetBytes(hash)
+func (p *PrivKey) signHedged(digest []byte, newHash func() hash.Hash, entropy io.Reader) (*Signature, error) {
+	if err := p.Curve.checkOrder(); err != nil {
+		return nil, err
+	}
 
-	l := n.BitLen()
-	if len(hash)*8 > l {
-		h.Rsh(h, uint(len(hash)*8-l))
+	if entropy == nil {
+		entropy = rand.Reader
+	}
+
+	extra := make([]byte, newHash().Size())
+	if _, err := io.ReadFull(entropy, extra); err != nil {
+		return nil, err
+	}
+
+	nonces := newRFC6979(p.D, p.Curve.N, digest, extra, newHash)
+	return p.sign(digest, func() (*big.Int, error) { return nonces.next(), nil })
+}
+
+// The nextK function is called for a new k (in the range [1, n-1]) whenever r or s is zero.
+// The signature is normalized to low s if requested by LowS. Callers check the curve order first (see checkOrder).
+func (p *PrivKey) sign(hash []byte, nextK func() (*big.Int, error)) (*Signature, error) {
+	n := p.Curve.N
+	ct, err := ctCurveFor(p.Curve)
+	if err != nil {
+		return nil, err
+	}
+	if ct.n == nil {
+		return nil, errors.New("curve order too large")
 	}
+	f := ct.n
+
+	h := hashToInt(hash, n)
 
 	var r, s *big.Int
+	var v byte
 	for {
-		// Generate a random integer k in the range [1, n-1]
-		k, err := rand.Int(rand.Reader, new(big.Int).Sub(n, big.NewInt(1)))
+		k, err := nextK()
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
-		k.Add(k, big.NewInt(1))
 
-		q := g.Multiply(k)
+		q, err := p.Curve.TryMultiplyGenerator(k)
+		if err != nil {
+			return nil, err
+		}
+		if q.AtInf {
+			return nil, errors.New("k * g = o, the order of g is not n")
+		}
 
 		r = new(big.Int).Mod(q.X, n)
 		if r.Cmp(big.NewInt(0)) == 0 {
@@ -274,22 +281,62 @@ func (p *PrivKey) Sign(msg []byte, hashFunc func([]byte) []byte) (*big.Int, *big
 			r.Neg(r)
 			r.Mod(r, n)
 		}
+		v = byte(q.Y.Bit(0))
 
-		left := new(big.Int).ModInverse(k, n)
-		right := new(big.Int).Mul(r, p.D)
-		right.Add(right, h)
-		s = new(big.Int).Mul(left, right)
-		s.Mod(s, n)
+		// s = k^-1 * (h + r * d) mod n, in constant time with respect to k and d
+		{
+			kInv := f.toMontgomery(k)
+			f.inv(kInv, kInv)
+
+			right := f.toMontgomery(p.D)
+			f.mul(right, right, f.toMontgomery(r))
+			f.add(right, right, f.toMontgomery(h))
+
+			f.mul(right, right, kInv)
+			s = f.fromMontgomery(right)
+		}
 		if s.Cmp(big.NewInt(0)) == 0 {
 			continue
 		}
-		if s.Sign() == -1 {
-			s.Neg(s)
-			s.Mod(s, n)
-		}
 
 		break
 	}
 
-	return r, s
+	// The recovery id is the parity of y (assuming x < n, see SetRecoveryID)
+	sig := &Signature{R: r, S: s, V: v, Curve: p.Curve}
+	if p.LowS {
+		sig.Normalize()
+	}
+	return sig, nil
+}
+
+// Signing requires the (odd, for the constant-time scalar arithmetic) order n, ie at least n > 2
+func (c *Curve) checkOrder() error {
+	if c.N == nil || c.N.Cmp(big.NewInt(2)) <= 0 || c.N.Bit(0) == 0 {
+		return errors.New("curve order missing or even")
+	}
+	return nil
+}
+
+// The leftmost n.BitLen() bits of the hash as an integer
+func hashToInt(hash []byte, n *big.Int) *big.Int {
+	h := new(big.Int).SetBytes(hash)
+
+	l := n.BitLen()
+	if len(hash)*8 > l {
+		h.Rsh(h, uint(len(hash)*8-l))
+	}
+	return h
+}
+
+func hashReader(r io.Reader, h crypto.Hash) ([]byte, error) {
+	if !h.Available() {
+		return nil, fmt.Errorf("hash function unavailable: %s", h)
+	}
+
+	hh := h.New()
+	if _, err := io.Copy(hh, r); err != nil {
+		return nil, err
+	}
+	return hh.Sum(nil), nil
 }
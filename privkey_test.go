@@ -1,10 +1,20 @@
 package ecdsa_tools
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
 	"crypto/rand"
+	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
 	"errors"
+	"io"
 	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 )
 
@@ -54,7 +64,11 @@ func TestSign(t *testing.T) {
 		t.Fatalf("expected z to be %x, got %x", expectedZ, z)
 	}
 
-	r, s := privkey.Sign(msgBytes, hashFunc)
+	sig, err := privkey.SignMessage(msgBytes, hashFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, s := sig.R, sig.S
 
 	if r.Cmp(expectedR) != 0 {
 		t.Errorf("expected r to be %x, got %x", expectedR, r)
@@ -62,4 +76,249 @@ func TestSign(t *testing.T) {
 	if s.Cmp(expectedSLow) != 0 && s.Cmp(expectedSHigh) != 0 {
 		t.Errorf("expected s to be %x or %x, got %x", expectedSLow, expectedSHigh, s)
 	}
+
+	// The same nonce supplied directly
+	sig2, err := privkey.SignWithNonce(msgBytes, hashFunc, func() *big.Int { return new(big.Int).Add(k, big.NewInt(1)) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig2.R.Cmp(r) != 0 || sig2.S.Cmp(s) != 0 {
+		t.Errorf("expected (%x, %x), got (%x, %x)", r, s, sig2.R, sig2.S)
+	}
+}
+
+func TestSignLowS(t *testing.T) {
+	privkey, err := NewPrivKeyBitcoin("deadbeef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	privkey.LowS = true
+	pubkey := privkey.CalcPubKey()
+
+	msg := []byte("Message for ECDSA signing")
+	hashFunc := func(data []byte) []byte {
+		rv := sha256.Sum256(data)
+		return rv[:]
+	}
+
+	// About half would otherwise be high
+	for i := 0; i < 16; i++ {
+		sig, err := privkey.SignMessage(msg, hashFunc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sig.IsLowS() || !pubkey.VerifyStrict(sig.R, sig.S, msg, hashFunc) {
+			t.Fatal("low s verification failed")
+		}
+
+		// The recovery id follows the normalization
+		v := sig.V
+		if err := sig.SetRecoveryID(pubkey, hashFunc(msg)); err != nil || sig.V != v {
+			t.Fatalf("recovery id mismatch: %v", err)
+		}
+	}
+
+	// Deterministic signatures are normalized too
+	privkey.LowS = false
+	sig, err := privkey.SignDeterministic(msg, sha256.New)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privkey.LowS = true
+	low, err := privkey.SignDeterministic(msg, sha256.New)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig.Normalize()
+	if low.S.Cmp(sig.S) != 0 || low.V != sig.V {
+		t.Error("deterministic low s mismatch")
+	}
+}
+
+func TestSignInvalidOrder(t *testing.T) {
+	secp256k1 := curves["secp256k1"]
+	msg := []byte("Message for ECDSA signing")
+	hashFunc := func(data []byte) []byte {
+		rv := sha256.Sum256(data)
+		return rv[:]
+	}
+
+	for _, n := range []*big.Int{nil, big.NewInt(2), new(big.Int).Add(secp256k1.N, big.NewInt(1))} {
+		curve := *secp256k1
+		curve.N = n
+		privkey := &PrivKey{D: big.NewInt(0xdeadbeef), Curve: &curve}
+
+		if _, err := privkey.SignMessage(msg, hashFunc); err == nil {
+			t.Errorf("n = %v: random nonce signature", n)
+		}
+		if _, err := privkey.SignDeterministic(msg, sha256.New); err == nil {
+			t.Errorf("n = %v: deterministic signature", n)
+		}
+		if _, err := privkey.SignWithNonce(msg, hashFunc, func() *big.Int { return big.NewInt(7) }); err == nil {
+			t.Errorf("n = %v: given nonce signature", n)
+		}
+
+		// Checked before reading any entropy
+		if _, err := privkey.SignHedged(msg, sha256.New, bytes.NewReader(nil)); err == nil || errors.Is(err, io.EOF) {
+			t.Errorf("n = %v: hedged signature: %v", n, err)
+		}
+	}
+}
+
+func BenchmarkSign(b *testing.B) {
+	privkey := &PrivKey{D: big.NewInt(0xdeadbeef), Curve: curves["secp256k1"]}
+
+	msgBytes := []byte("Message for ECDSA signing")
+	hashFunc := func(data []byte) []byte {
+		rv := sha256.Sum256(data)
+		return rv[:]
+	}
+
+	for i := 0; i < b.N; i++ {
+		if _, err := privkey.SignMessage(msgBytes, hashFunc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSignOpenSSL(t *testing.T) {
+	if _, err := exec.LookPath("openssl"); err != nil {
+		t.Skip("openssl not found")
+	}
+
+	// Digests both shorter and longer than the order (eg sha1 with secp521r1, sha512 with prime256v1)
+	hashes := []struct {
+		name     string
+		hashFunc func([]byte) []byte
+	}{
+		{"sha1", func(data []byte) []byte { rv := sha1.Sum(data); return rv[:] }},
+		{"sha256", func(data []byte) []byte { rv := sha256.Sum256(data); return rv[:] }},
+		{"sha512", func(data []byte) []byte { rv := sha512.Sum512(data); return rv[:] }},
+	}
+
+	dir := t.TempDir()
+	msgPath := filepath.Join(dir, "msg")
+	sigPath := filepath.Join(dir, "sig")
+	privKeyPath := filepath.Join(dir, "privkey.pem")
+	pubKeyPath := filepath.Join(dir, "pubkey.pem")
+
+	msg := []byte("Message for ECDSA signing")
+	if err := os.WriteFile(msgPath, msg, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range Curves() {
+		curve := c.Name
+		if _, err := execStdout("", "openssl", "ecparam", "-name", curve, "-genkey", "-out", privKeyPath); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := execStdout("", "openssl", "ec", "-in", privKeyPath, "-pubout", "-out", pubKeyPath); err != nil {
+			t.Fatal(err)
+		}
+
+		privkey, err := NewPrivKeyViaOpenSSLFile(privKeyPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubkey, err := NewPubKeyViaOpenSSLFile(pubKeyPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, h := range hashes {
+			// openssl signs, verified here
+			if _, err := execStdout("", "openssl", "dgst", "-"+h.name, "-sign", privKeyPath, "-out", sigPath, msgPath); err != nil {
+				t.Fatal(err)
+			}
+			encodedSignature, err := os.ReadFile(sigPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var signature []*big.Int
+			if _, err := asn1.Unmarshal(encodedSignature, &signature); err != nil || len(signature) != 2 {
+				t.Fatalf("%s %s: invalid openssl signature", curve, h.name)
+			}
+			if !pubkey.Verify(signature[0], signature[1], msg, h.hashFunc) {
+				t.Errorf("%s %s: openssl signature verification failed", curve, h.name)
+			}
+
+			// Signed here, verified by openssl
+			sig, err := privkey.SignMessage(msg, h.hashFunc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			encodedSignature, err = sig.MarshalDER()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(sigPath, encodedSignature, 0600); err != nil {
+				t.Fatal(err)
+			}
+			if output, err := execStdout("", "openssl", "dgst", "-"+h.name, "-verify", pubKeyPath, "-signature", sigPath, msgPath); err != nil || output != "Verified OK\n" {
+				t.Errorf("%s %s: openssl verification failed: %v", curve, h.name, err)
+			}
+		}
+	}
+}
+
+func TestSignReader(t *testing.T) {
+	msg := bytes.Repeat([]byte("Message for ECDSA signing "), 1<<12)
+
+	for _, curve := range []string{"prime256v1", "secp384r1", "secp521r1"} {
+		privkey, err := NewRandomPrivKeyViaStdLib(curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubkey := privkey.CalcPubKey()
+		stdPubKey, err := pubkey.ToStdLib()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Digests both shorter and longer than n, the latter truncated to the leftmost bits as by crypto/ecdsa
+		for _, h := range []crypto.Hash{crypto.SHA256, crypto.SHA512} {
+			sig, err := privkey.SignReader(bytes.NewReader(msg), h)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, s := sig.R, sig.S
+
+			digest := h.New()
+			digest.Write(msg)
+			if !ecdsa.Verify(stdPubKey, digest.Sum(nil), r, s) {
+				t.Errorf("%s %s: crypto/ecdsa verification failed", curve, h)
+			}
+			if !pubkey.VerifyDigest(r, s, digest.Sum(nil)) {
+				t.Errorf("%s %s: digest verification failed", curve, h)
+			}
+			if ok, err := pubkey.VerifyReader(r, s, bytes.NewReader(msg), h); err != nil || !ok {
+				t.Errorf("%s %s: reader verification failed", curve, h)
+			}
+			if ok, err := pubkey.VerifyReader(r, s, bytes.NewReader(msg[1:]), h); err != nil || ok {
+				t.Errorf("%s %s: modified message verified", curve, h)
+			}
+			sig.Normalize()
+			if ok, err := pubkey.VerifyReaderStrict(sig.R, sig.S, bytes.NewReader(msg), h); err != nil || !ok {
+				t.Errorf("%s %s: strict reader verification failed", curve, h)
+			}
+			high := new(big.Int).Sub(privkey.Curve.N, sig.S)
+			if ok, err := pubkey.VerifyReaderStrict(sig.R, high, bytes.NewReader(msg), h); err != nil || ok {
+				t.Errorf("%s %s: high s strict reader verification succeeded", curve, h)
+			}
+
+			// A digest signed directly
+			if sig, err = privkey.SignDigest(digest.Sum(nil)); err != nil {
+				t.Fatal(err)
+			}
+			if !ecdsa.Verify(stdPubKey, digest.Sum(nil), sig.R, sig.S) {
+				t.Errorf("%s %s: crypto/ecdsa verification of digest signature failed", curve, h)
+			}
+		}
+	}
+
+	privkey := &PrivKey{D: big.NewInt(1), Curve: curves["secp256k1"]}
+	if _, err := privkey.SignReader(bytes.NewReader(nil), crypto.Hash(0)); err == nil {
+		t.Error("expected unavailable hash error")
+	}
 }
@@ -1,11 +1,13 @@
 package ecdsa_tools
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"encoding/pem"
 	"errors"
-	"fmt"
+	"io"
 	"math/big"
-	"slices"
-	"strings"
+	"os"
 )
 
 type PubKey struct {
@@ -13,79 +15,69 @@ type PubKey struct {
 	Curve *Curve
 }
 
+// Accepts PEM or DER encoded SubjectPublicKeyInfo files, eg those produced by openssl ec -pubout
 func NewPubKeyViaOpenSSLFile(pubKeyPath string) (*PubKey, error) {
-	decodedPubKey, err := execStdout("", "openssl", "ec", "-text", "-noout", "-in", pubKeyPath, "-pubin")
+	data, err := os.ReadFile(pubKeyPath)
 	if err != nil {
 		return nil, err
 	}
 
-	decodedPubKeyLines := strings.Split(decodedPubKey, "\n")
-
-	curve, err := extractField(decodedPubKeyLines, "ASN1 OID:")
-	if err != nil {
-		return nil, err
-	}
-	if !slices.Contains(supportedCurves, curve) {
-		return nil, fmt.Errorf("unsupported curve: %s", curve)
-	}
-
-	hexPubKey, err := extractHexKey(decodedPubKeyLines, "pub:")
-	if err != nil {
-		return nil, err
+	if block, _ := pem.Decode(data); block != nil {
+		return NewPubKeyFromPEM(data)
 	}
+	return NewPubKeyFromPKIX(data)
+}
 
-	if len(hexPubKey) != 130 || hexPubKey[0:2] != "04" {
-		return nil, errors.New("unexpected pubkey format")
-	}
+func NewPubKeyEthereum(address string) (*PubKey, error) {
+	return nil, errors.New("TODO implement")
+}
 
-	c := curves[curve]
-	pubkey := &Point{X: new(big.Int), Y: new(big.Int), Curve: c}
-	if _, ok := pubkey.X.SetString(hexPubKey[2:2+64], 16); !ok {
-		return nil, errors.New("invalid hex value")
-	}
-	if _, ok := pubkey.Y.SetString(hexPubKey[2+64:2+64+64], 16); !ok {
-		return nil, errors.New("invalid hex value")
+// Reports whether x is the same public key, either a *PubKey or a *crypto/ecdsa.PublicKey
+// (as expected of crypto.PublicKey implementations, eg by crypto/tls)
+func (p *PubKey) Equal(x crypto.PublicKey) bool {
+	var q *PubKey
+	switch x := x.(type) {
+	case *PubKey:
+		q = x
+	case *ecdsa.PublicKey:
+		var err error
+		if q, err = NewPubKeyFromStdLib(x); err != nil {
+			return false
+		}
+	default:
+		return false
 	}
 
-	if !pubkey.OnCurve() {
-		return nil, errors.New("pubkey not on curve")
-	}
+	return p.Curve.Equals(q.Curve) && p.E.Equals(q.E)
+}
 
-	return &PubKey{E: pubkey, Curve: c}, nil
+func (p *PubKey) Verify(r, s *big.Int, msg []byte, hashFunc func([]byte) []byte) bool {
+	return p.VerifyDigest(r, s, hashFunc(msg))
 }
 
-func NewPubKeyEthereum(address string) (*PubKey, error) {
-	return nil, errors.New("TODO implement")
+// Verifies the hash of everything read from rd (until EOF), eg for streaming large files
+func (p *PubKey) VerifyReader(r, s *big.Int, rd io.Reader, h crypto.Hash) (bool, error) {
+	digest, err := hashReader(rd, h)
+	if err != nil {
+		return false, err
+	}
+	return p.VerifyDigest(r, s, digest), nil
 }
 
-func (p *PubKey) Verify(r, s *big.Int, msg []byte, hashFunc func([]byte) []byte) bool {
+// Verifies against an already computed message hash, the leftmost n.BitLen() bits of the digest are used
+func (p *PubKey) VerifyDigest(r, s *big.Int, digest []byte) bool {
 	n := p.Curve.N
 	g := &Point{X: p.Curve.Gx, Y: p.Curve.Gy, Curve: p.Curve}
 
-	if p.E.AtInf || !p.E.OnCurve() {
+	if !p.E.Curve.Equals(p.Curve) {
 		return false
 	}
-
-	// Verify n * E = O (point at infinity)
-
-	multiplyEByN := func() (err error) {
-		defer func() {
-			if r := recover(); r != nil {
-				if e, ok := r.(error); ok {
-					err = e
-				}
-			}
-		}()
-
-		p.E.Multiply(n)
-		return
+	if err := p.E.Validate(); err != nil {
+		return false
 	}
 
-	if err := multiplyEByN(); err != nil {
-		if errMsg := err.Error(); errMsg != "multiplied point not on curve" {
-			return false
-		}
-	} else {
+	// Verify n * E = O (point at infinity)
+	if q, err := p.E.TryMultiply(n); err != nil || !q.AtInf {
 		return false
 	}
 
@@ -95,13 +87,7 @@ func (p *PubKey) Verify(r, s *big.Int, msg []byte, hashFunc func([]byte) []byte)
 		}
 	}
 
-	hash := hashFunc(msg)
-	h := new(big.Int).SetBytes(hash)
-
-	l := n.BitLen()
-	if len(hash)*8 > l {
-		h.Rsh(h, uint(len(hash)*8-l))
-	}
+	h := hashToInt(digest, n)
 
 	w := new(big.Int).ModInverse(s, n)
 	u := new(big.Int).Mul(h, w)
@@ -109,8 +95,8 @@ func (p *PubKey) Verify(r, s *big.Int, msg []byte, hashFunc func([]byte) []byte)
 	v := new(big.Int).Mul(r, w)
 	v.Mod(v, n)
 
-	q := g.Multiply(u).Add(p.E.Multiply(v))
-	if q.AtInf {
+	q, err := g.TryDoubleScalarMultiply(u, p.E, v)
+	if err != nil || q.AtInf {
 		return false
 	}
 
@@ -122,3 +108,22 @@ func (p *PubKey) Verify(r, s *big.Int, msg []byte, hashFunc func([]byte) []byte)
 
 	return r.Cmp(x) == 0
 }
+
+// Also rejects high s signatures (see Signature.IsLowS), ie non-standard on Bitcoin and Ethereum
+func (p *PubKey) VerifyStrict(r, s *big.Int, msg []byte, hashFunc func([]byte) []byte) bool {
+	return p.VerifyDigestStrict(r, s, hashFunc(msg))
+}
+
+// VerifyReader rejecting high s signatures, see VerifyStrict
+func (p *PubKey) VerifyReaderStrict(r, s *big.Int, rd io.Reader, h crypto.Hash) (bool, error) {
+	digest, err := hashReader(rd, h)
+	if err != nil {
+		return false, err
+	}
+	return p.VerifyDigestStrict(r, s, digest), nil
+}
+
+// VerifyDigest rejecting high s signatures, see VerifyStrict
+func (p *PubKey) VerifyDigestStrict(r, s *big.Int, digest []byte) bool {
+	return isLowS(s, p.Curve.N) && p.VerifyDigest(r, s, digest)
+}
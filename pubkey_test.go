@@ -42,4 +42,38 @@ func TestVerify(t *testing.T) {
 	if !pubkey.Verify(r, sHigh, msgBytes, hashFunc) {
 		t.Errorf("(r, sLow) verification failed")
 	}
+
+	if !pubkey.VerifyStrict(r, sLow, msgBytes, hashFunc) {
+		t.Errorf("(r, sLow) strict verification failed")
+	}
+	if pubkey.VerifyStrict(r, sHigh, msgBytes, hashFunc) {
+		t.Errorf("(r, sHigh) strict verification succeeded")
+	}
+
+	atInf := &PubKey{E: &Point{AtInf: true, Curve: pubkey.Curve}, Curve: pubkey.Curve}
+	if atInf.Verify(r, sLow, msgBytes, hashFunc) {
+		t.Errorf("verification with pubkey at infinity succeeded")
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	privkey := &PrivKey{D: big.NewInt(0xdeadbeef), Curve: curves["secp256k1"]}
+	pubkey := privkey.CalcPubKey()
+
+	msgBytes := []byte("Message for ECDSA signing")
+	hashFunc := func(data []byte) []byte {
+		rv := sha256.Sum256(data)
+		return rv[:]
+	}
+	sig, err := privkey.SignMessage(msgBytes, hashFunc)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !pubkey.Verify(sig.R, sig.S, msgBytes, hashFunc) {
+			b.Fatal("verification failed")
+		}
+	}
 }
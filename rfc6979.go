@@ -0,0 +1,95 @@
+package ecdsa_tools
+
+import (
+	"crypto/hmac"
+	"hash"
+	"math/big"
+)
+
+// Deterministic generation of k per RFC 6979 section 3.2 (HMAC-DRBG)
+type rfc6979 struct {
+	q       *big.Int
+	newHash func() hash.Hash
+	k, v    []byte
+}
+
+// The extra data (k' of section 3.6) is optional and mixed into the initial seeding
+func newRFC6979(x, q *big.Int, h1 []byte, extra []byte, newHash func() hash.Hash) *rfc6979 {
+	g := &rfc6979{q: q, newHash: newHash}
+
+	hlen := newHash().Size()
+	g.v = make([]byte, hlen)
+	for i := range g.v {
+		g.v[i] = 0x01
+	}
+	g.k = make([]byte, hlen)
+
+	xOctets := g.int2octets(x)
+	hOctets := g.bits2octets(h1)
+
+	g.k = g.hmac(g.v, []byte{0x00}, xOctets, hOctets, extra)
+	g.v = g.hmac(g.v)
+	g.k = g.hmac(g.v, []byte{0x01}, xOctets, hOctets, extra)
+	g.v = g.hmac(g.v)
+
+	return g
+}
+
+// Returns the next candidate k in the range [1, q-1] (section 3.2 step h)
+func (g *rfc6979) next() *big.Int {
+	qlen := g.q.BitLen()
+
+	for {
+		var t []byte
+		for len(t)*8 < qlen {
+			g.v = g.hmac(g.v)
+			t = append(t, g.v...)
+		}
+
+		k := g.bits2int(t)
+
+		// Update the state beforehand in case another k is requested
+		g.k = g.hmac(g.v, []byte{0x00})
+		g.v = g.hmac(g.v)
+
+		if k.Sign() == 1 && k.Cmp(g.q) == -1 {
+			return k
+		}
+	}
+}
+
+func (g *rfc6979) hmac(data ...[]byte) []byte {
+	mac := hmac.New(g.newHash, g.k)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+// Section 2.3.2
+func (g *rfc6979) bits2int(b []byte) *big.Int {
+	v := new(big.Int).SetBytes(b)
+
+	qlen := g.q.BitLen()
+	if len(b)*8 > qlen {
+		v.Rsh(v, uint(len(b)*8-qlen))
+	}
+
+	return v
+}
+
+// Section 2.3.3
+func (g *rfc6979) int2octets(v *big.Int) []byte {
+	b := make([]byte, (g.q.BitLen()+7)/8)
+	v.FillBytes(b)
+	return b
+}
+
+// Section 2.3.4
+func (g *rfc6979) bits2octets(b []byte) []byte {
+	z := g.bits2int(b)
+	if z.Cmp(g.q) >= 0 {
+		z.Sub(z, g.q)
+	}
+	return g.int2octets(z)
+}
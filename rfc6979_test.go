@@ -0,0 +1,146 @@
+package ecdsa_tools
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+func TestRFC6979(t *testing.T) {
+	strToBigInt := func(s string) *big.Int {
+		rv, ok := new(big.Int).SetString(s, 0)
+		if !ok {
+			t.Fatal("invalid string")
+		}
+		return rv
+	}
+
+	hashFunc := func(data []byte) []byte {
+		rv := sha256.Sum256(data)
+		return rv[:]
+	}
+
+	table := []struct {
+		curve     string
+		d         *big.Int
+		msg       string
+		k         *big.Int
+		r         *big.Int
+		sLow      *big.Int
+		canonical bool // s as published, otherwise either s or n - s
+	}{
+		// RFC 6979 appendix A.2.5 (P-256 with SHA-256)
+		{
+			"prime256v1",
+			strToBigInt("0xc9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721"),
+			"sample",
+			strToBigInt("0xa6e3c57dd01abe90086538398355dd4c3b17aa873382b0f24d6129493d8aad60"),
+			strToBigInt("0xefd48b2aacb6a8fd1140dd9cd45e81d69d2c877b56aaf991c34d0ea84eaf3716"),
+			strToBigInt("0xf7cb1c942d657c41d436c7a1b6e29f65f3e900dbb9aff4064dc4ab2f843acda8"),
+			true,
+		},
+		{
+			"prime256v1",
+			strToBigInt("0xc9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721"),
+			"test",
+			strToBigInt("0xd16b6ae827f17175e040871a1c7ec3500192c4c92677336ec2537acaee0008e0"),
+			strToBigInt("0xf1abb023518351cd71d881567b1ea663ed3efcf6c5132b354f28d3b0b7d38367"),
+			strToBigInt("0x019f4113742a2b14bd25926b49c649155f267e60d3814b4c0cc84250e46f0083"),
+			true,
+		},
+
+		// Widely used secp256k1 vector (eg python-ecdsa, trezor-crypto), published with low s
+		{
+			"secp256k1",
+			big.NewInt(1),
+			"Satoshi Nakamoto",
+			strToBigInt("0x8f8a276c19f4149656b280621e358cce24f5f52542772691ee69063b74f15d15"),
+			strToBigInt("0x934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d8"),
+			strToBigInt("0x2442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5"),
+			false,
+		},
+	}
+
+	for _, entry := range table {
+		privkey := &PrivKey{D: entry.d, Curve: curves[entry.curve]}
+		n := privkey.Curve.N
+
+		k := newRFC6979(entry.d, n, hashFunc([]byte(entry.msg)), nil, sha256.New).next()
+		if k.Cmp(entry.k) != 0 {
+			t.Errorf("%s %q: expected k to be %x, got %x", entry.curve, entry.msg, entry.k, k)
+		}
+
+		sig, err := privkey.SignDeterministic([]byte(entry.msg), sha256.New)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, s := sig.R, sig.S
+		if r.Cmp(entry.r) != 0 {
+			t.Errorf("%s %q: expected r to be %x, got %x", entry.curve, entry.msg, entry.r, r)
+		}
+
+		sHigh := new(big.Int).Sub(n, entry.sLow)
+		if s.Cmp(entry.sLow) != 0 && (entry.canonical || s.Cmp(sHigh) != 0) {
+			t.Errorf("%s %q: expected s to be %x, got %x", entry.curve, entry.msg, entry.sLow, s)
+		}
+
+		if !privkey.CalcPubKey().Verify(r, s, []byte(entry.msg), hashFunc) {
+			t.Errorf("%s %q: verification failed", entry.curve, entry.msg)
+		}
+
+		// Signing is reproducible
+		if sig2, err := privkey.SignDeterministic([]byte(entry.msg), sha256.New); err != nil || sig2.R.Cmp(r) != 0 || sig2.S.Cmp(s) != 0 {
+			t.Errorf("%s %q: signature not deterministic", entry.curve, entry.msg)
+		}
+	}
+}
+
+func TestSignHedged(t *testing.T) {
+	privkey := &PrivKey{D: big.NewInt(1), Curve: curves["secp256k1"]}
+	pubkey := privkey.CalcPubKey()
+	msg := []byte("Satoshi Nakamoto")
+
+	hashFunc := func(data []byte) []byte {
+		rv := sha256.Sum256(data)
+		return rv[:]
+	}
+
+	entropy := bytes.Repeat([]byte{0x5a}, sha256.Size)
+
+	sig1, err := privkey.SignHedged(msg, sha256.New, bytes.NewReader(entropy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pubkey.Verify(sig1.R, sig1.S, msg, hashFunc) {
+		t.Errorf("verification failed")
+	}
+
+	// A fixed entropy stream is reproducible
+	sig2, err := privkey.SignHedged(msg, sha256.New, bytes.NewReader(entropy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig1.R.Cmp(sig2.R) != 0 || sig1.S.Cmp(sig2.S) != 0 {
+		t.Errorf("signature not reproducible with fixed entropy")
+	}
+
+	// But differs from fully deterministic signing and from other entropy
+	if sig, err := privkey.SignDeterministic(msg, sha256.New); err != nil || sig.R.Cmp(sig1.R) == 0 {
+		t.Errorf("entropy not mixed in")
+	}
+	sig3, err := privkey.SignHedged(msg, sha256.New, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig3.R.Cmp(sig1.R) == 0 {
+		t.Errorf("entropy not mixed in")
+	}
+	if !pubkey.Verify(sig3.R, sig3.S, msg, hashFunc) {
+		t.Errorf("verification failed")
+	}
+
+	if _, err := privkey.SignHedged(msg, sha256.New, bytes.NewReader(entropy[:4])); err == nil {
+		t.Errorf("expected short entropy read to fail")
+	}
+}
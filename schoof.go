@@ -0,0 +1,308 @@
+package ecdsa_tools
+
+import (
+	"errors"
+	"math/big"
+)
+
+// Schoof's algorithm (https://en.wikipedia.org/wiki/Schoof%27s_algorithm).
+//
+// Frobenius phi(x, y) = (x^p, y^p) satisfies phi^2 - t * phi + p = 0 on every point, where t is the trace.
+// Restricted to the l-torsion E[l] (the points P with l * P = O, whose x coordinates are the roots of the
+// division polynomial psi_l) this gives t mod l as the tau in [0, l) with phi^2(P) + (p mod l) * P = tau * phi(P).
+// The computation uses the generic point (x, y) with polynomial arithmetic modulo psi_l and y^2 = x^3 + ax + b.
+// Repeating for small primes l until their product exceeds 4 * sqrt(p) determines t by the CRT.
+// (The SEA improvements using modular polynomials to work with factors of psi_l are not implemented.)
+
+func (c *Curve) CountPointsSchoof() (*big.Int, error) {
+	if err := c.checkCountable(); err != nil {
+		return nil, err
+	}
+
+	s := newSchoof(c)
+
+	// t mod 2 = 0 iff there is a point of order two, ie x^3 + ax + b has a root: gcd(x^p - x, x^3 + ax + b) != 1
+	xp := s.pf.newModulus(s.f).pow(s.pf.newPoly(big.NewInt(0), big.NewInt(1)), c.P)
+	t, modulus := big.NewInt(1), big.NewInt(2)
+	if s.pf.degree(s.pf.gcd(s.pf.sub(xp, s.pf.newPoly(big.NewInt(0), big.NewInt(1))), s.f)) > 0 {
+		t.SetInt64(0)
+	}
+
+	// The product of the primes must exceed the width of the Hasse interval
+	bound := new(big.Int).Sqrt(new(big.Int).Lsh(c.P, 4))
+	bound.Add(bound, big.NewInt(1))
+
+	for l := int64(3); modulus.Cmp(bound) <= 0; l += 2 {
+		bl := big.NewInt(l)
+		if !bl.ProbablyPrime(20) || bl.Cmp(c.P) == 0 {
+			continue
+		}
+
+		tl, err := s.traceModL(int(l))
+		if err != nil {
+			return nil, err
+		}
+
+		// CRT: t' = t + modulus * ((tl - t) / modulus mod l)
+		k := new(big.Int).Sub(big.NewInt(int64(tl)), t)
+		k.Mul(k, new(big.Int).ModInverse(modulus, bl))
+		k.Mod(k, bl)
+		t.Add(t, k.Mul(k, modulus))
+		modulus.Mul(modulus, bl)
+	}
+
+	// t in (-modulus / 2, modulus / 2]
+	if t.Cmp(new(big.Int).Rsh(modulus, 1)) > 0 {
+		t.Sub(t, modulus)
+	}
+
+	points := new(big.Int).Add(c.P, big.NewInt(1))
+	points.Sub(points, t)
+	if !hasseBound(c.P, points) {
+		return nil, errors.New("trace outside the hasse bound")
+	}
+	return points, nil
+}
+
+type schoof struct {
+	curve *Curve
+	pf    *polyField
+	f     poly   // x^3 + ax + b
+	psi   []poly // Division polynomials, psi_n / y for even n
+}
+
+func newSchoof(c *Curve) *schoof {
+	pf := &polyField{p: c.P}
+	return &schoof{
+		curve: c,
+		pf:    pf,
+		f:     pf.newPoly(c.B, c.A, big.NewInt(0), big.NewInt(1)),
+	}
+}
+
+// Returns psi_n for odd n and psi_n / y for even n (as polynomials in x)
+func (s *schoof) divisionPoly(n int) poly {
+	pf, a, b := s.pf, s.curve.A, s.curve.B
+
+	for len(s.psi) <= n {
+		m := len(s.psi) / 2
+		var next poly
+
+		switch k := len(s.psi); {
+		case k == 0:
+			next = nil
+		case k == 1:
+			next = pf.newPoly(big.NewInt(1))
+		case k == 2:
+			next = pf.newPoly(big.NewInt(2))
+		case k == 3:
+			// 3x^4 + 6ax^2 + 12bx - a^2
+			next = pf.newPoly(
+				new(big.Int).Neg(new(big.Int).Mul(a, a)),
+				new(big.Int).Mul(b, big.NewInt(12)),
+				new(big.Int).Mul(a, big.NewInt(6)),
+				big.NewInt(0),
+				big.NewInt(3),
+			)
+		case k == 4:
+			// 4 * (x^6 + 5ax^4 + 20bx^3 - 5a^2x^2 - 4abx - 8b^2 - a^3)
+			a2 := new(big.Int).Mul(a, a)
+			c0 := new(big.Int).Mul(b, b)
+			c0.Mul(c0, big.NewInt(-8))
+			c0.Sub(c0, new(big.Int).Mul(a2, a))
+			next = pf.scale(pf.newPoly(
+				c0,
+				new(big.Int).Mul(new(big.Int).Mul(a, b), big.NewInt(-4)),
+				new(big.Int).Mul(a2, big.NewInt(-5)),
+				new(big.Int).Mul(b, big.NewInt(20)),
+				new(big.Int).Mul(a, big.NewInt(5)),
+				big.NewInt(0),
+				big.NewInt(1),
+			), big.NewInt(4))
+		case k%2 == 1:
+			// psi_2m+1 = psi_m+2 * psi_m^3 - psi_m-1 * psi_m+1^3, the even terms contributing a factor of y^4 = f^2
+			cube := func(v poly) poly { return pf.mul(v, pf.mul(v, v)) }
+			f2 := pf.mul(s.f, s.f)
+			left := pf.mul(s.psi[m+2], cube(s.psi[m]))
+			right := pf.mul(s.psi[m-1], cube(s.psi[m+1]))
+			if m%2 == 0 {
+				left = pf.mul(left, f2)
+			} else {
+				right = pf.mul(right, f2)
+			}
+			next = pf.sub(left, right)
+		default:
+			// psi_2m = psi_m * (psi_m+2 * psi_m-1^2 - psi_m-2 * psi_m+1^2) / 2y, the y factors cancelling
+			sq := func(v poly) poly { return pf.mul(v, v) }
+			next = pf.mul(s.psi[m], pf.sub(
+				pf.mul(s.psi[m+2], sq(s.psi[m-1])),
+				pf.mul(s.psi[m-2], sq(s.psi[m+1])),
+			))
+			next = pf.scale(next, new(big.Int).ModInverse(big.NewInt(2), s.curve.P))
+		}
+
+		s.psi = append(s.psi, next)
+	}
+
+	return s.psi[n]
+}
+
+// A point (X(x), y * Y(x)) in E(F_p[x, y] / (h(x), y^2 - f(x))), nil being the point at infinity
+type schoofPoint struct {
+	X, Y poly
+}
+
+// Returned when an inverse modulo h does not exist, factor is a non-trivial factor of h
+type schoofFactorError struct {
+	factor poly
+}
+
+func (e *schoofFactorError) Error() string {
+	return "non-trivial factor of the modulus"
+}
+
+// Error with the factor gcd(v, h), which must be non-trivial since v is non-zero yet vanishes for some points
+func (s *schoof) factorOf(v, h poly) error {
+	if g := s.pf.gcd(v, h); s.pf.degree(g) > 0 {
+		return &schoofFactorError{g}
+	}
+	return errors.New("inconsistent y coordinates")
+}
+
+func (s *schoof) traceModL(l int) (int, error) {
+	h := s.pf.monic(s.divisionPoly(l))
+
+	for {
+		tl, err := s.traceModLWith(l, h)
+
+		var factorErr *schoofFactorError
+		if errors.As(err, &factorErr) {
+			// Any factor works since a single l-torsion point determines the trace
+			h = factorErr.factor
+			continue
+		}
+
+		return tl, err
+	}
+}
+
+func (s *schoof) traceModLWith(l int, h poly) (int, error) {
+	pf, p := s.pf, s.curve.P
+	m := pf.newModulus(h)
+	f := m.reduce(s.f)
+
+	// phi(P) = (x^p, y^p) = (x^p, y * f^((p - 1) / 2)) and phi^2(P) = (x^p^2, y * (f^((p - 1) / 2))^(p + 1))
+	xp := m.pow(pf.newPoly(big.NewInt(0), big.NewInt(1)), p)
+	yp := m.pow(f, new(big.Int).Rsh(p, 1))
+	phi := &schoofPoint{X: xp, Y: yp}
+	phi2 := &schoofPoint{X: m.pow(xp, p), Y: m.mul(m.pow(yp, p), yp)}
+
+	// phi^2(P) + (p mod l) * P
+	generic := &schoofPoint{X: m.reduce(pf.newPoly(big.NewInt(0), big.NewInt(1))), Y: pf.newPoly(big.NewInt(1))}
+	q, err := s.multiply(generic, new(big.Int).Mod(p, big.NewInt(int64(l))), f, m)
+	if err != nil {
+		return 0, err
+	}
+	lhs, err := s.add(phi2, q, f, m)
+	if err != nil {
+		return 0, err
+	}
+	if lhs == nil {
+		return 0, nil
+	}
+
+	// Compare with tau * phi(P), matching x coordinates give tau or -tau
+	t := phi
+	for tau := 1; tau <= l/2; tau++ {
+		dx := pf.sub(t.X, lhs.X)
+		if len(dx) == 0 {
+			if pf.equal(t.Y, lhs.Y) {
+				return tau, nil
+			}
+			if len(pf.add(t.Y, lhs.Y)) == 0 {
+				return l - tau, nil
+			}
+			return 0, s.factorOf(pf.sub(t.Y, lhs.Y), h)
+		}
+
+		// The x coordinates may match for only some of the l-torsion points
+		if g := pf.gcd(dx, h); pf.degree(g) > 0 {
+			return 0, &schoofFactorError{g}
+		}
+
+		if t, err = s.add(t, phi, f, m); err != nil {
+			return 0, err
+		}
+	}
+
+	return 0, errors.New("trace not found")
+}
+
+func (s *schoof) add(p1, p2 *schoofPoint, f poly, m *polyModulus) (*schoofPoint, error) {
+	pf := s.pf
+
+	if p1 == nil {
+		return p2, nil
+	}
+	if p2 == nil {
+		return p1, nil
+	}
+
+	dx := pf.sub(p2.X, p1.X)
+	if len(dx) == 0 {
+		if pf.equal(p1.Y, p2.Y) {
+			return s.double(p1, f, m)
+		}
+		if len(pf.add(p1.Y, p2.Y)) == 0 {
+			return nil, nil
+		}
+		return nil, s.factorOf(pf.sub(p1.Y, p2.Y), m.h)
+	}
+
+	// lambda = y * (Y2 - Y1) / (X2 - X1) = y * L
+	inv, g := pf.invMod(dx, m.h)
+	if pf.degree(g) > 0 {
+		return nil, &schoofFactorError{g}
+	}
+	return s.fromSlope(m.mul(pf.sub(p2.Y, p1.Y), inv), p1, p2.X, f, m), nil
+}
+
+func (s *schoof) double(p1 *schoofPoint, f poly, m *polyModulus) (*schoofPoint, error) {
+	pf := s.pf
+
+	if p1 == nil || len(p1.Y) == 0 {
+		return nil, nil
+	}
+
+	// lambda = (3X^2 + a) / (2yY) = y * (3X^2 + a) / (2fY) = y * L
+	num := pf.add(pf.scale(m.mul(p1.X, p1.X), big.NewInt(3)), pf.newPoly(s.curve.A))
+	inv, g := pf.invMod(pf.scale(m.mul(f, p1.Y), big.NewInt(2)), m.h)
+	if pf.degree(g) > 0 {
+		return nil, &schoofFactorError{g}
+	}
+	return s.fromSlope(m.mul(num, inv), p1, p1.X, f, m), nil
+}
+
+// X3 = lambda^2 - X1 - X2 = f * L^2 - X1 - X2, y * Y3 = lambda * (X1 - X3) - y * Y1
+func (s *schoof) fromSlope(l poly, p1 *schoofPoint, x2, f poly, m *polyModulus) *schoofPoint {
+	pf := s.pf
+	x3 := pf.sub(pf.sub(m.mul(f, m.mul(l, l)), p1.X), x2)
+	y3 := pf.sub(m.mul(l, pf.sub(p1.X, x3)), p1.Y)
+	return &schoofPoint{X: x3, Y: y3}
+}
+
+func (s *schoof) multiply(p1 *schoofPoint, k *big.Int, f poly, m *polyModulus) (*schoofPoint, error) {
+	var q *schoofPoint
+	var err error
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		if q, err = s.double(q, f, m); err != nil {
+			return nil, err
+		}
+		if k.Bit(i) == 1 {
+			if q, err = s.add(q, p1, f, m); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return q, nil
+}
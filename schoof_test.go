@@ -0,0 +1,78 @@
+package ecdsa_tools
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestCountPointsSchoof(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+
+	for _, bits := range []int{8, 12, 16} {
+		for i := 0; i < 8; i++ {
+			curve := randomCurve(r, bits)
+
+			expected, err := curve.CountPointsNaive()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual, err := curve.CountPointsSchoof(); err != nil {
+				t.Fatal(err)
+			} else if actual.Cmp(expected) != 0 {
+				t.Errorf("p = %s, a = %s, b = %s: %s != %s", curve.P, curve.A, curve.B, actual, expected)
+			}
+		}
+	}
+
+	for _, bits := range []int{32, 40} {
+		curve := randomCurve(r, bits)
+
+		expected, err := curve.CountPointsBSGS()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual, err := curve.CountPointsSchoof(); err != nil {
+			t.Fatal(err)
+		} else if actual.Cmp(expected) != 0 {
+			t.Errorf("p = %s, a = %s, b = %s: %s != %s", curve.P, curve.A, curve.B, actual, expected)
+		}
+	}
+}
+
+func TestDivisionPolynomials(t *testing.T) {
+	curve := &Curve{P: big.NewInt(1009), A: big.NewInt(2), B: big.NewInt(3)}
+	s := newSchoof(curve)
+
+	// deg psi_n = (n^2 - 1) / 2 for odd n and deg(psi_n / y) = (n^2 - 4) / 2 for even n
+	for n := 1; n < 16; n++ {
+		expected := (n*n - 1) / 2
+		if n%2 == 0 {
+			expected = (n*n - 4) / 2
+		}
+		if degree := s.pf.degree(s.divisionPoly(n)); degree != expected {
+			t.Errorf("psi_%d: degree %d != %d", n, degree, expected)
+		}
+	}
+
+	// The roots of psi_3 are the x coordinates of the points of order 3
+	psi3 := s.divisionPoly(3)
+	for x := int64(0); x < 1009; x++ {
+		rhs := curve.rhs(new(big.Int), big.NewInt(x))
+		y, ok := modSqrt(rhs, curve.P)
+		if !ok || y.Sign() == 0 {
+			continue
+		}
+
+		p := newJacobianPoint(&Point{X: big.NewInt(x), Y: y, Curve: curve})
+		orderThree := p.multiply(big.NewInt(3)).atInf()
+
+		v := new(big.Int)
+		for i := len(psi3) - 1; i >= 0; i-- {
+			v.Mul(v, big.NewInt(x)).Add(v, psi3[i]).Mod(v, curve.P)
+		}
+		if orderThree != (v.Sign() == 0) {
+			t.Errorf("x = %d: order three %v, psi_3(x) = %s", x, orderThree, v)
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package ecdsa_tools
+
+import (
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Signature encodings, all requiring r and s in [1, n-1]
+// - DER: SEQUENCE { r INTEGER, s INTEGER } per RFC 3279 section 2.2.3 (X.509, TLS, OpenSSL)
+// - IEEE P1363: r || s as fixed length big-endian integers the byte length of n (WebCrypto, JWS, PKCS#11)
+// - EIP-2098: r || (v << top bit) | s, which requires low s (leaving the top bit free)
+// - RSV: r || s || v with v being 27 + the recovery id (Ethereum)
+// The recovery id V is the parity of the y coordinate of k * G, set when signing or by SetRecoveryID.
+
+var (
+	ErrInvalidSignature       = errors.New("invalid signature encoding")
+	ErrSignatureOutOfRange    = errors.New("signature value out of range")
+	ErrInvalidSignatureLength = errors.New("invalid signature encoding length")
+)
+
+type Signature struct {
+	R, S  *big.Int
+	V     byte // Recovery id, zero or one
+	Curve *Curve
+}
+
+// RFC 3279 section 2.2.3
+type ecdsaSigValue struct {
+	R, S *big.Int
+}
+
+// Strict DER, rejecting non-minimal (or otherwise non-canonical) encodings, negative values and trailing bytes
+func UnmarshalSignatureDER(curve *Curve, der []byte) (*Signature, error) {
+	if curve == nil {
+		return nil, errors.New("signature curve required")
+	}
+	var v ecdsaSigValue
+	rest, err := asn1.Unmarshal(der, &v)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSignature, err)
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%w: trailing bytes", ErrInvalidSignature)
+	}
+
+	// encoding/asn1 ignores any further sequence elements
+	if canonical, err := asn1.Marshal(v); err != nil || string(canonical) != string(der) {
+		return nil, fmt.Errorf("%w: non-canonical der", ErrInvalidSignature)
+	}
+
+	sig := &Signature{R: v.R, S: v.S, Curve: curve}
+	if err := sig.checkRange(); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+func UnmarshalSignatureP1363(curve *Curve, b []byte) (*Signature, error) {
+	if curve == nil {
+		return nil, errors.New("signature curve required")
+	}
+	size := curve.scalarSize()
+	if len(b) != 2*size {
+		return nil, ErrInvalidSignatureLength
+	}
+
+	sig := &Signature{R: new(big.Int).SetBytes(b[:size]), S: new(big.Int).SetBytes(b[size:]), Curve: curve}
+	if err := sig.checkRange(); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+func UnmarshalSignatureEIP2098(curve *Curve, b []byte) (*Signature, error) {
+	if curve == nil {
+		return nil, errors.New("signature curve required")
+	}
+	size := curve.scalarSize()
+	if len(b) != 2*size {
+		return nil, ErrInvalidSignatureLength
+	}
+
+	vs := new(big.Int).SetBytes(b[size:])
+	top := 8*size - 1
+	sig := &Signature{
+		R:     new(big.Int).SetBytes(b[:size]),
+		S:     new(big.Int).SetBit(vs, top, 0),
+		V:     byte(vs.Bit(top)),
+		Curve: curve,
+	}
+	if err := sig.checkRange(); err != nil {
+		return nil, err
+	}
+	if !sig.IsLowS() {
+		return nil, fmt.Errorf("%w: high s", ErrSignatureOutOfRange)
+	}
+	return sig, nil
+}
+
+// Accepts v as either the recovery id or 27 + the recovery id
+func UnmarshalSignatureRSV(curve *Curve, b []byte) (*Signature, error) {
+	if curve == nil {
+		return nil, errors.New("signature curve required")
+	}
+	size := curve.scalarSize()
+	if len(b) != 2*size+1 {
+		return nil, ErrInvalidSignatureLength
+	}
+
+	v := b[2*size]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return nil, fmt.Errorf("%w: v = %d", ErrInvalidSignature, b[2*size])
+	}
+
+	sig := &Signature{R: new(big.Int).SetBytes(b[:size]), S: new(big.Int).SetBytes(b[size : 2*size]), V: v, Curve: curve}
+	if err := sig.checkRange(); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+func (s *Signature) MarshalDER() ([]byte, error) {
+	if err := s.checkRange(); err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(ecdsaSigValue{R: s.R, S: s.S})
+}
+
+func (s *Signature) MarshalP1363() ([]byte, error) {
+	if err := s.checkRange(); err != nil {
+		return nil, err
+	}
+
+	size := s.Curve.scalarSize()
+	b := make([]byte, 2*size)
+	s.R.FillBytes(b[:size])
+	s.S.FillBytes(b[size:])
+	return b, nil
+}
+
+func (s *Signature) MarshalEIP2098() ([]byte, error) {
+	b, err := s.MarshalP1363()
+	if err != nil {
+		return nil, err
+	}
+
+	// Low s also leaves the top bit free for v (n/2 < 2^(8*size-1))
+	size := s.Curve.scalarSize()
+	if !s.IsLowS() {
+		return nil, fmt.Errorf("%w: high s, normalize first", ErrSignatureOutOfRange)
+	}
+	if s.V > 1 {
+		return nil, fmt.Errorf("%w: v = %d", ErrInvalidSignature, s.V)
+	}
+	b[size] |= s.V << 7
+	return b, nil
+}
+
+func (s *Signature) MarshalRSV() ([]byte, error) {
+	b, err := s.MarshalP1363()
+	if err != nil {
+		return nil, err
+	}
+	if s.V > 1 {
+		return nil, fmt.Errorf("%w: v = %d", ErrInvalidSignature, s.V)
+	}
+	return append(b, 27+s.V), nil
+}
+
+// DER, the curve must be set before unmarshalling
+func (s *Signature) MarshalBinary() ([]byte, error) {
+	return s.MarshalDER()
+}
+
+func (s *Signature) UnmarshalBinary(data []byte) error {
+	if s.Curve == nil {
+		return errors.New("signature curve required")
+	}
+
+	sig, err := UnmarshalSignatureDER(s.Curve, data)
+	if err != nil {
+		return err
+	}
+	*s = *sig
+	return nil
+}
+
+// Hex encoded DER, the curve must be set before unmarshalling
+func (s *Signature) MarshalText() ([]byte, error) {
+	der, err := s.MarshalDER()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(hex.EncodeToString(der)), nil
+}
+
+func (s *Signature) UnmarshalText(text []byte) error {
+	der, err := hex.DecodeString(string(text))
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSignature, err)
+	}
+	return s.UnmarshalBinary(der)
+}
+
+type signatureJSON struct {
+	Curve string `json:"curve"`
+	R     string `json:"r"`
+	S     string `json:"s"`
+	V     byte   `json:"v"`
+}
+
+// The curve by name and r and s as hex, eg {"curve":"secp256k1","r":"...","s":"...","v":0}
+func (s *Signature) MarshalJSON() ([]byte, error) {
+	if err := s.checkRange(); err != nil {
+		return nil, err
+	}
+	if s.Curve.Name == "" {
+		return nil, errors.New("curve without name")
+	}
+
+	size := s.Curve.scalarSize()
+	return json.Marshal(signatureJSON{
+		Curve: s.Curve.Name,
+		R:     hex.EncodeToString(s.R.FillBytes(make([]byte, size))),
+		S:     hex.EncodeToString(s.S.FillBytes(make([]byte, size))),
+		V:     s.V,
+	})
+}
+
+func (s *Signature) UnmarshalJSON(data []byte) error {
+	var v signatureJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	curve, err := CurveByName(v.Curve)
+	if err != nil {
+		return err
+	}
+	if v.V > 1 {
+		return fmt.Errorf("%w: v = %d", ErrInvalidSignature, v.V)
+	}
+
+	sig := &Signature{V: v.V, Curve: curve}
+	for _, field := range []struct {
+		dst **big.Int
+		src string
+	}{{&sig.R, v.R}, {&sig.S, v.S}} {
+		b, err := hex.DecodeString(field.src)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidSignature, err)
+		}
+		*field.dst = new(big.Int).SetBytes(b)
+	}
+
+	if err := sig.checkRange(); err != nil {
+		return err
+	}
+	*s = *sig
+	return nil
+}
+
+// Sets V by finding which of the two points with x = r recovers the pubkey (the rare r = x - n case is not handled)
+func (s *Signature) SetRecoveryID(pubkey *PubKey, digest []byte) error {
+	if err := s.checkRange(); err != nil {
+		return err
+	}
+
+	c := s.Curve
+	n := c.N
+	g := &Point{X: c.Gx, Y: c.Gy, Curve: c}
+
+	// Q = r^-1 * (s * R - z * G)
+	rInv := new(big.Int).ModInverse(s.R, n)
+	u := new(big.Int).Neg(hashToInt(digest, n))
+	u.Mul(u, rInv).Mod(u, n)
+	v := new(big.Int).Mul(s.S, rInv)
+	v.Mod(v, n)
+
+	for parity := uint(0); parity <= 1; parity++ {
+		y, err := c.decompressY(s.R, parity)
+		if err != nil {
+			return err
+		}
+
+		q, err := g.TryDoubleScalarMultiply(u, &Point{X: s.R, Y: y, Curve: c}, v)
+		if err != nil {
+			return err
+		}
+		if !q.AtInf && q.Equals(pubkey.E) {
+			s.V = byte(parity)
+			return nil
+		}
+	}
+
+	return errors.New("pubkey not recovered from signature")
+}
+
+// Whether s is in the lower half [1, n/2] as required by Bitcoin (BIP 62) and Ethereum (EIP-2)
+func (s *Signature) IsLowS() bool {
+	return isLowS(s.S, s.Curve.N)
+}
+
+func isLowS(s, n *big.Int) bool {
+	return s.Cmp(new(big.Int).Rsh(n, 1)) != 1
+}
+
+// Replaces a high s with n - s, (r, s) and (r, n - s) are both valid as the latter corresponds to -k.
+// The recovery id is flipped accordingly as -k * G has the opposite y parity.
+func (s *Signature) Normalize() {
+	if s.IsLowS() {
+		return
+	}
+	s.S = new(big.Int).Sub(s.Curve.N, s.S)
+	s.V ^= 1
+}
+
+func (s *Signature) checkRange() error {
+	if s.Curve == nil || s.R == nil || s.S == nil {
+		return errors.New("incomplete signature")
+	}
+
+	for _, v := range []*big.Int{s.R, s.S} {
+		if v.Sign() != 1 || v.Cmp(s.Curve.N) != -1 {
+			return ErrSignatureOutOfRange
+		}
+	}
+	return nil
+}
+
+func (c *Curve) scalarSize() int {
+	return (c.N.BitLen() + 7) / 8
+}
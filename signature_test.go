@@ -0,0 +1,229 @@
+package ecdsa_tools
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestSignatureEncodings(t *testing.T) {
+	for _, curve := range []string{"secp256k1", "prime256v1", "secp521r1", "brainpoolP384r1"} {
+		privkey, err := NewRandomPrivKeyViaOpenSSL(curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := privkey.Curve
+		pubkey := privkey.CalcPubKey()
+
+		digest := sha256.Sum256([]byte("Message for ECDSA signing"))
+		sig, err := privkey.SignDigest(digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signedV := sig.V
+		if err := sig.SetRecoveryID(pubkey, digest[:]); err != nil {
+			t.Fatalf("%s: %v", curve, err)
+		}
+		if sig.V != signedV {
+			t.Errorf("%s: signing recovery id mismatch", curve)
+		}
+
+		// Normalizing keeps the signature valid and the recovery id correct
+		sig.Normalize()
+		if !sig.IsLowS() || !pubkey.VerifyDigestStrict(sig.R, sig.S, digest[:]) {
+			t.Fatalf("%s: normalized verification failed", curve)
+		}
+		v := sig.V
+		if err := sig.SetRecoveryID(pubkey, digest[:]); err != nil || sig.V != v {
+			t.Fatalf("%s: normalized recovery id mismatch", curve)
+		}
+
+		high := &Signature{R: sig.R, S: new(big.Int).Sub(c.N, sig.S), V: sig.V ^ 1, Curve: c}
+		if high.IsLowS() || pubkey.VerifyDigestStrict(high.R, high.S, digest[:]) {
+			t.Errorf("%s: high s accepted", curve)
+		}
+		if _, err := high.MarshalEIP2098(); !errors.Is(err, ErrSignatureOutOfRange) {
+			t.Errorf("%s: high s eip-2098 encoded", curve)
+		}
+		high.Normalize()
+		if high.S.Cmp(sig.S) != 0 || high.V != sig.V {
+			t.Errorf("%s: normalize mismatch", curve)
+		}
+
+		table := []struct {
+			name      string
+			marshal   func() ([]byte, error)
+			unmarshal func(*Curve, []byte) (*Signature, error)
+			length    int
+		}{
+			{"der", sig.MarshalDER, UnmarshalSignatureDER, 0},
+			{"p1363", sig.MarshalP1363, UnmarshalSignatureP1363, 2 * c.scalarSize()},
+			{"eip2098", sig.MarshalEIP2098, UnmarshalSignatureEIP2098, 2 * c.scalarSize()},
+			{"rsv", sig.MarshalRSV, UnmarshalSignatureRSV, 2*c.scalarSize() + 1},
+		}
+
+		for _, entry := range table {
+			b, err := entry.marshal()
+			if err != nil {
+				t.Fatalf("%s %s: %v", curve, entry.name, err)
+			}
+			if entry.length != 0 && len(b) != entry.length {
+				t.Errorf("%s %s: unexpected length %d", curve, entry.name, len(b))
+			}
+
+			sig2, err := entry.unmarshal(c, b)
+			if err != nil {
+				t.Fatalf("%s %s: %v", curve, entry.name, err)
+			}
+			if sig2.R.Cmp(sig.R) != 0 || sig2.S.Cmp(sig.S) != 0 || !sig2.Curve.Equals(c) {
+				t.Errorf("%s %s: round trip mismatch", curve, entry.name)
+			}
+			if (entry.name == "eip2098" || entry.name == "rsv") && sig2.V != sig.V {
+				t.Errorf("%s %s: recovery id mismatch", curve, entry.name)
+			}
+
+			if _, err := entry.unmarshal(c, append(b, 0)); err == nil {
+				t.Errorf("%s %s: trailing byte accepted", curve, entry.name)
+			}
+		}
+
+		if !pubkey.VerifyDigest(sig.R, sig.S, digest[:]) {
+			t.Errorf("%s: verification failed", curve)
+		}
+
+		// Text and json
+		text, err := sig.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig3 := &Signature{Curve: c}
+		if err := sig3.UnmarshalText(text); err != nil || sig3.R.Cmp(sig.R) != 0 || sig3.S.Cmp(sig.S) != 0 {
+			t.Errorf("%s: text round trip mismatch: %v", curve, err)
+		}
+
+		data, err := json.Marshal(sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sig4 Signature
+		if err := json.Unmarshal(data, &sig4); err != nil {
+			t.Fatalf("%s: %v", curve, err)
+		}
+		if sig4.R.Cmp(sig.R) != 0 || sig4.S.Cmp(sig.S) != 0 || sig4.V != sig.V || !sig4.Curve.Equals(c) {
+			t.Errorf("%s: json round trip mismatch", curve)
+		}
+	}
+}
+
+func TestSignatureDER(t *testing.T) {
+	curve := curves["secp256k1"]
+
+	// The high bit of r requires a leading zero byte
+	sig := &Signature{R: big.NewInt(0x80), S: big.NewInt(0x7f), Curve: curve}
+	der, err := sig.MarshalDER()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []byte{0x30, 0x07, 0x02, 0x02, 0x00, 0x80, 0x02, 0x01, 0x7f}; !bytes.Equal(der, expected) {
+		t.Fatalf("unexpected encoding %x", der)
+	}
+
+	// SEQUENCE { INTEGER 00 || n, INTEGER 1 }
+	n := append([]byte{0x02, byte(len(curve.N.Bytes()) + 1), 0x00}, curve.N.Bytes()...)
+	n = append(append([]byte{0x30, byte(len(n) + 3)}, n...), 0x02, 0x01, 0x01)
+	table := []struct {
+		name string
+		der  string
+		err  error
+	}{
+		{"trailing bytes", "3006020101020102" + "00", ErrInvalidSignature},
+		{"non-minimal integer", "30070202000102010" + "2", ErrInvalidSignature},
+		{"non-minimal length", "308106020101020102", ErrInvalidSignature},
+		{"extra element", "3009020101020102020103", ErrInvalidSignature},
+		{"negative value", "30060201ff020102", ErrSignatureOutOfRange},
+		{"zero value", "3006020100020102", ErrSignatureOutOfRange},
+		{"value n", hex.EncodeToString(n), ErrSignatureOutOfRange},
+	}
+
+	for _, entry := range table {
+		b, err := hex.DecodeString(entry.der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := UnmarshalSignatureDER(curve, b); !errors.Is(err, entry.err) {
+			t.Errorf("%s: unexpected error: %v", entry.name, err)
+		}
+	}
+
+	if _, err := (&Signature{R: big.NewInt(1), S: curve.N, Curve: curve}).MarshalDER(); !errors.Is(err, ErrSignatureOutOfRange) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := new(Signature).UnmarshalBinary(der); err == nil {
+		t.Error("expected curve required error")
+	}
+	for _, unmarshal := range []func(*Curve, []byte) (*Signature, error){
+		UnmarshalSignatureDER, UnmarshalSignatureP1363, UnmarshalSignatureEIP2098, UnmarshalSignatureRSV,
+	} {
+		if _, err := unmarshal(nil, der); err == nil {
+			t.Error("expected curve required error")
+		}
+	}
+}
+
+func TestSignatureEIP2098(t *testing.T) {
+	// n/2 < 2^383 < n so high s values in (n/2, 2^383) have the top bit clear
+	curve := curves["brainpoolP384r1"]
+	half := new(big.Int).Rsh(curve.N, 1)
+	size := curve.scalarSize()
+
+	for _, entry := range []struct {
+		s   *big.Int
+		err error
+	}{
+		{half, nil},
+		{new(big.Int).Add(half, big.NewInt(1)), ErrSignatureOutOfRange},
+		{new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 383), big.NewInt(1)), ErrSignatureOutOfRange},
+	} {
+		sig := &Signature{R: big.NewInt(1), S: entry.s, V: 1, Curve: curve}
+		if _, err := sig.MarshalEIP2098(); !errors.Is(err, entry.err) {
+			t.Errorf("%x: unexpected marshal error: %v", entry.s, err)
+		}
+
+		b := make([]byte, 2*size)
+		sig.R.FillBytes(b[:size])
+		sig.S.FillBytes(b[size:])
+		b[size] |= 0x80
+		if _, err := UnmarshalSignatureEIP2098(curve, b); !errors.Is(err, entry.err) {
+			t.Errorf("%x: unexpected unmarshal error: %v", entry.s, err)
+		}
+	}
+}
+
+func TestSignatureStdLib(t *testing.T) {
+	privkey, err := NewRandomPrivKeyViaStdLib("prime256v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdPubKey, err := privkey.CalcPubKey().ToStdLib()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest := sha256.Sum256([]byte("Message for ECDSA signing"))
+	sig, err := privkey.SignDigest(digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := sig.MarshalDER()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ecdsa.VerifyASN1(stdPubKey, digest[:], der) {
+		t.Error("crypto/ecdsa verification failed")
+	}
+}
@@ -0,0 +1,59 @@
+package ecdsa_tools
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"io"
+)
+
+// crypto.Signer implementation, eg for x509.CreateCertificate or tls.Certificate.PrivateKey
+
+var _ crypto.Signer = (*PrivKey)(nil)
+
+// A *crypto/ecdsa.PublicKey for the curves supported by the standard library (as expected by crypto/x509
+// and crypto/tls), otherwise a *PubKey. Both implement Equal(crypto.PublicKey), PubKey.Equal accepting either type.
+func (p *PrivKey) Public() crypto.PublicKey {
+	pubkey := p.CalcPubKey()
+	if stdPubKey, err := pubkey.ToStdLib(); err == nil {
+		return stdPubKey
+	}
+	return pubkey
+}
+
+// Options for Sign, eg &SignerOpts{Hash: crypto.SHA256, LowS: true}
+type SignerOpts struct {
+	Hash crypto.Hash
+	LowS bool // Normalize s to the lower half (see Signature.Normalize)
+}
+
+func (o *SignerOpts) HashFunc() crypto.Hash {
+	return o.Hash
+}
+
+// Signs the (already hashed) digest returning an ASN.1 DER signature. The nonce is derived per RFC 6979
+// with entropy from rand mixed in (see SignHedged), using the hash from opts or SHA-256 if none.
+// Pass a *SignerOpts with LowS set (or set PrivKey.LowS) for signatures accepted by VerifyDigestStrict.
+func (p *PrivKey) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	newHash := sha256.New
+	if opts != nil {
+		if h := opts.HashFunc(); h != 0 {
+			if !h.Available() {
+				return nil, fmt.Errorf("hash function unavailable: %s", h)
+			}
+			if len(digest) != h.Size() {
+				return nil, fmt.Errorf("digest length %d does not match %s", len(digest), h)
+			}
+			newHash = h.New
+		}
+	}
+
+	sig, err := p.signHedged(digest, newHash, rand)
+	if err != nil {
+		return nil, err
+	}
+	if o, ok := opts.(*SignerOpts); ok && o.LowS {
+		sig.Normalize() // Otherwise as set by the LowS field
+	}
+	return sig.MarshalDER()
+}
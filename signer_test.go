@@ -0,0 +1,122 @@
+package ecdsa_tools
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestSigner(t *testing.T) {
+	for _, c := range Curves() {
+		curve := c.Name
+		privkey, err := NewRandomPrivKeyViaOpenSSL(curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubkey := privkey.CalcPubKey()
+
+		digest := sha512.Sum384([]byte("Message for ECDSA signing"))
+		der, err := privkey.Sign(rand.Reader, digest[:], crypto.SHA384)
+		if err != nil {
+			t.Fatalf("%s: %v", curve, err)
+		}
+		sig, err := UnmarshalSignatureDER(privkey.Curve, der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pubkey.VerifyDigest(sig.R, sig.S, digest[:]) {
+			t.Errorf("%s: verification failed", curve)
+		}
+
+		switch public := privkey.Public().(type) {
+		case *ecdsa.PublicKey:
+			if !ecdsa.VerifyASN1(public, digest[:], der) {
+				t.Errorf("%s: crypto/ecdsa verification failed", curve)
+			}
+		case *PubKey:
+			if _, err := c.StdCurve(); err == nil || !public.E.Equals(pubkey.E) {
+				t.Errorf("%s: unexpected public key", curve)
+			}
+		default:
+			t.Errorf("%s: unexpected public key type %T", curve, public)
+		}
+
+		// Either type compares equal to itself and to the *PubKey
+		public, ok := privkey.Public().(interface{ Equal(crypto.PublicKey) bool })
+		if !ok || !public.Equal(privkey.Public()) || !pubkey.Equal(privkey.Public()) {
+			t.Errorf("%s: public key not equal", curve)
+		}
+		other := (&PrivKey{D: big.NewInt(2), Curve: privkey.Curve}).Public()
+		if public.Equal(other) || pubkey.Equal(other) || pubkey.Equal(digest) {
+			t.Errorf("%s: different public keys equal", curve)
+		}
+
+		if _, err := privkey.Sign(rand.Reader, digest[:], crypto.SHA256); err == nil {
+			t.Errorf("%s: expected digest length mismatch", curve)
+		}
+
+		// Low s signatures across several nonces (about half would otherwise be high)
+		for i := 0; i < 8; i++ {
+			der, err := privkey.Sign(rand.Reader, digest[:], &SignerOpts{Hash: crypto.SHA384, LowS: true})
+			if err != nil {
+				t.Fatalf("%s: %v", curve, err)
+			}
+			sig, err := UnmarshalSignatureDER(privkey.Curve, der)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !sig.IsLowS() || !pubkey.VerifyDigestStrict(sig.R, sig.S, digest[:]) {
+				t.Errorf("%s: low s verification failed", curve)
+			}
+		}
+	}
+}
+
+func TestSignerCertificate(t *testing.T) {
+	privkey, err := NewRandomPrivKeyViaStdLib("prime256v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ecdsa-tools"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, privkey.Public(), privkey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cert.CheckSignatureFrom(cert); err != nil {
+		t.Error(err)
+	}
+
+	// The certificate signature also verifies with Verify
+	sig, err := UnmarshalSignatureDER(privkey.Curve, cert.Signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashFunc := func(data []byte) []byte {
+		rv := sha256.Sum256(data)
+		return rv[:]
+	}
+	if !privkey.CalcPubKey().Verify(sig.R, sig.S, cert.RawTBSCertificate, hashFunc) {
+		t.Error("certificate signature verification failed")
+	}
+}
@@ -0,0 +1,108 @@
+package ecdsa_tools
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Conversions to and from the standard library crypto/ecdsa keys, for use with crypto/tls, crypto/x509 and the like.
+// Only the registry curves implemented by crypto/elliptic are supported.
+
+var stdCurves = map[string]func() elliptic.Curve{
+	"prime256v1": elliptic.P256,
+	"secp384r1":  elliptic.P384,
+	"secp521r1":  elliptic.P521,
+}
+
+// The crypto/elliptic implementation of the curve
+func (c *Curve) StdCurve() (elliptic.Curve, error) {
+	for name, stdCurve := range stdCurves {
+		if d, err := CurveByName(name); err == nil && c.Equals(d) {
+			return stdCurve(), nil
+		}
+	}
+	return nil, fmt.Errorf("curve not supported by crypto/elliptic: %s", c.Name)
+}
+
+func curveFromStd(stdCurve elliptic.Curve) (*Curve, error) {
+	if stdCurve == nil {
+		return nil, errors.New("missing curve")
+	}
+
+	params := stdCurve.Params()
+	for name := range stdCurves {
+		c, err := CurveByName(name)
+		if err != nil {
+			return nil, err
+		}
+		if c.P.Cmp(params.P) == 0 && c.N.Cmp(params.N) == 0 && c.B.Cmp(params.B) == 0 &&
+			c.Gx.Cmp(params.Gx) == 0 && c.Gy.Cmp(params.Gy) == 0 {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported curve: %s", params.Name)
+}
+
+func NewPrivKeyFromStdLib(k *ecdsa.PrivateKey) (*PrivKey, error) {
+	curve, err := curveFromStd(k.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	if k.D == nil || k.D.Sign() != 1 || k.D.Cmp(curve.N) >= 0 {
+		return nil, errors.New("invalid privkey value")
+	}
+	privkey := &PrivKey{D: new(big.Int).Set(k.D), Curve: curve}
+
+	// The public key is optional but must match if present
+	if k.X != nil || k.Y != nil {
+		pubkey, err := NewPubKeyFromStdLib(&k.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		if !pubkey.E.Equals(privkey.CalcPubKey().E) {
+			return nil, errors.New("pubkey privkey mismatch")
+		}
+	}
+
+	return privkey, nil
+}
+
+func NewPubKeyFromStdLib(k *ecdsa.PublicKey) (*PubKey, error) {
+	curve, err := curveFromStd(k.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	if k.X == nil || k.Y == nil {
+		return nil, errors.New("missing pubkey coordinates")
+	}
+	e, err := NewPoint(new(big.Int).Set(k.X), new(big.Int).Set(k.Y), curve)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PubKey{E: e, Curve: curve}, nil
+}
+
+func (p *PrivKey) ToStdLib() (*ecdsa.PrivateKey, error) {
+	pubkey, err := p.CalcPubKey().ToStdLib()
+	if err != nil {
+		return nil, err
+	}
+	return &ecdsa.PrivateKey{PublicKey: *pubkey, D: new(big.Int).Set(p.D)}, nil
+}
+
+func (p *PubKey) ToStdLib() (*ecdsa.PublicKey, error) {
+	stdCurve, err := p.Curve.StdCurve()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.E.Validate(); err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{Curve: stdCurve, X: new(big.Int).Set(p.E.X), Y: new(big.Int).Set(p.E.Y)}, nil
+}
@@ -0,0 +1,96 @@
+package ecdsa_tools
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"math/big"
+	"testing"
+)
+
+func TestStdLib(t *testing.T) {
+	hashFunc := func(data []byte) []byte {
+		rv := sha256.Sum256(data)
+		return rv[:]
+	}
+	msg := []byte("Message for ECDSA signing")
+
+	for _, curve := range []string{"prime256v1", "secp384r1", "secp521r1"} {
+		privkey, err := NewRandomPrivKeyViaStdLib(curve)
+		if err != nil {
+			t.Fatalf("%s: %v", curve, err)
+		}
+		pubkey := privkey.CalcPubKey()
+
+		stdPrivKey, err := privkey.ToStdLib()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stdPrivKey.Curve.Params().BitSize != privkey.Curve.BitSize {
+			t.Errorf("%s: unexpected std curve %s", curve, stdPrivKey.Curve.Params().Name)
+		}
+
+		// Round trip
+		privkey2, err := NewPrivKeyFromStdLib(stdPrivKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if privkey2.D.Cmp(privkey.D) != 0 || !privkey2.Curve.Equals(privkey.Curve) {
+			t.Errorf("%s: privkey round trip mismatch", curve)
+		}
+		pubkey2, err := NewPubKeyFromStdLib(&stdPrivKey.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pubkey2.E.Equals(pubkey.E) {
+			t.Errorf("%s: pubkey round trip mismatch", curve)
+		}
+
+		// Signatures in both directions
+		sig, err := privkey.SignMessage(msg, hashFunc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ecdsa.Verify(&stdPrivKey.PublicKey, hashFunc(msg), sig.R, sig.S) {
+			t.Errorf("%s: crypto/ecdsa verification failed", curve)
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, stdPrivKey, hashFunc(msg))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pubkey.Verify(r, s, msg, hashFunc) {
+			t.Errorf("%s: verification of crypto/ecdsa signature failed", curve)
+		}
+
+		// Usable with crypto/x509
+		der, err := x509.MarshalPKIXPublicKey(&stdPrivKey.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pubkey3, err := NewPubKeyFromPKIX(der); err != nil || !pubkey3.E.Equals(pubkey.E) {
+			t.Errorf("%s: x509 pubkey mismatch", curve)
+		}
+	}
+
+	// Not implemented by crypto/elliptic
+	for _, curve := range []string{"secp256k1", "brainpoolP256r1"} {
+		if _, err := NewRandomPrivKeyViaStdLib(curve); err == nil {
+			t.Errorf("%s: expected unsupported curve", curve)
+		}
+		if _, err := (&PrivKey{D: big.NewInt(1), Curve: curves[curve]}).ToStdLib(); err == nil {
+			t.Errorf("%s: expected unsupported curve", curve)
+		}
+	}
+
+	// Mismatched public key
+	stdPrivKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdPrivKey.D.Add(stdPrivKey.D, big.NewInt(1))
+	if _, err := NewPrivKeyFromStdLib(stdPrivKey); err == nil {
+		t.Error("expected pubkey privkey mismatch")
+	}
+}
@@ -0,0 +1,120 @@
+package ecdsa_tools
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Simultaneous multi-scalar multiplication (Straus / Shamir's trick) with interleaved wNAF.
+//
+// Each scalar is recoded in width-w non-adjacent form, digits are zero or odd with |d| < 2^(w-1)
+// and any w consecutive digits contain at most one non-zero digit. A single chain of doublings is shared
+// by all the points, at each step adding (or subtracting) a precomputed odd multiple for each non-zero digit.
+// Not constant time, for use with public scalars only (eg signature verification).
+
+const strausWindow = 5
+
+// Computes u * p + v * q
+func (p *Point) DoubleScalarMultiply(u *big.Int, q *Point, v *big.Int) *Point {
+	return mustPoint(p.TryDoubleScalarMultiply(u, q, v))
+}
+
+func (p *Point) TryDoubleScalarMultiply(u *big.Int, q *Point, v *big.Int) (*Point, error) {
+	if !p.Curve.Equals(q.Curve) {
+		return nil, ErrCurveMismatch
+	}
+	for _, point := range []*Point{p, q} {
+		if !point.AtInf && !point.OnCurve() {
+			return nil, ErrNotOnCurve
+		}
+	}
+
+	points := []*jacobianPoint{newJacobianPoint(p), newJacobianPoint(q)}
+	scalars := []*big.Int{u, v}
+
+	if glv := p.Curve.glv; glv != nil {
+		points, scalars = glv.expand(points, scalars, p.Curve.N)
+	}
+
+	r := straus(points, scalars).toAffine()
+	if !r.AtInf && !r.OnCurve() {
+		return nil, fmt.Errorf("multiplied %w", ErrNotOnCurve)
+	}
+
+	return r, nil
+}
+
+func straus(points []*jacobianPoint, scalars []*big.Int) *jacobianPoint {
+	curve := points[0].Curve
+
+	nafs := make([][]int8, len(points))
+	tables := make([][]*jacobianPoint, len(points))
+	maxLen := 0
+
+	for i, p := range points {
+		k := scalars[i]
+		if k.Sign() == -1 {
+			p = p.negate()
+			k = new(big.Int).Neg(k)
+		}
+
+		nafs[i] = wnaf(k, strausWindow)
+		if len(nafs[i]) > maxLen {
+			maxLen = len(nafs[i])
+		}
+
+		// Odd multiples p, 3p, 5p, ..., (2^(w-1) - 1)p
+		table := make([]*jacobianPoint, 1<<(strausWindow-2))
+		table[0] = p
+		twoP := p.double()
+		for j := 1; j < len(table); j++ {
+			table[j] = table[j-1].add(twoP)
+		}
+		tables[i] = table
+	}
+
+	acc := newJacobianInf(curve)
+	for bit := maxLen - 1; bit >= 0; bit-- {
+		acc = acc.double()
+
+		for i, naf := range nafs {
+			if bit >= len(naf) {
+				continue
+			}
+
+			if d := naf[bit]; d > 0 {
+				acc = acc.add(tables[i][d/2])
+			} else if d < 0 {
+				acc = acc.add(tables[i][-d/2].negate())
+			}
+		}
+	}
+
+	return acc
+}
+
+// Width-w non-adjacent form of a non-negative k, least significant digit first
+func wnaf(k *big.Int, w uint) []int8 {
+	k = new(big.Int).Set(k)
+
+	window := int64(1) << w
+	mask := big.NewInt(window - 1)
+
+	naf := make([]int8, 0, k.BitLen()+1)
+	for k.Sign() > 0 {
+		var d int64
+		if k.Bit(0) == 1 {
+			// d = k mods 2^w, ie in the range (-2^(w-1), 2^(w-1))
+			d = new(big.Int).And(k, mask).Int64()
+			if d >= window/2 {
+				d -= window
+			}
+			k.Sub(k, big.NewInt(d))
+		}
+
+		naf = append(naf, int8(d))
+		k.Rsh(k, 1)
+	}
+
+	return naf
+}
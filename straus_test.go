@@ -0,0 +1,84 @@
+package ecdsa_tools
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestWNAF(t *testing.T) {
+	for i := 0; i < 64; i++ {
+		k, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 256))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		naf := wnaf(k, strausWindow)
+
+		v := new(big.Int)
+		lastNonZero := -int(strausWindow)
+		for j := len(naf) - 1; j >= 0; j-- {
+			v.Lsh(v, 1)
+			v.Add(v, big.NewInt(int64(naf[j])))
+
+			if d := naf[j]; d != 0 {
+				if d%2 == 0 || d >= 1<<(strausWindow-1) || d <= -1<<(strausWindow-1) {
+					t.Errorf("invalid digit %d", d)
+				}
+				if lastNonZero-j < strausWindow && lastNonZero >= 0 {
+					t.Errorf("adjacent non-zero digits")
+				}
+				lastNonZero = j
+			}
+		}
+
+		if v.Cmp(k) != 0 {
+			t.Errorf("wnaf of %x evaluates to %x", k, v)
+		}
+	}
+}
+
+func TestDoubleScalarMultiply(t *testing.T) {
+	for name, curve := range curves {
+		g := &Point{X: curve.Gx, Y: curve.Gy, Curve: curve}
+		q := g.Multiply(big.NewInt(0x1234567))
+
+		for i := 0; i < 8; i++ {
+			u, _ := rand.Int(rand.Reader, curve.N)
+			v, _ := rand.Int(rand.Reader, curve.N)
+
+			expected := g.Multiply(u).Add(q.Multiply(v))
+			if !g.DoubleScalarMultiply(u, q, v).Equals(expected) {
+				t.Errorf("%s: u * g + v * q mismatch", name)
+			}
+		}
+
+		// Negative scalars and a result at infinity
+		if !g.DoubleScalarMultiply(big.NewInt(-3), q, big.NewInt(2)).Equals(g.Multiply(big.NewInt(-3)).Add(q.Double())) {
+			t.Errorf("%s: negative scalar mismatch", name)
+		}
+		if !g.DoubleScalarMultiply(big.NewInt(0x1234567), q, big.NewInt(-1)).AtInf {
+			t.Errorf("%s: expected point at infinity", name)
+		}
+	}
+}
+
+func BenchmarkDoubleScalarMultiply(b *testing.B) {
+	curve := curves["secp256k1"]
+	g := &Point{X: curve.Gx, Y: curve.Gy, Curve: curve}
+	q := g.Multiply(big.NewInt(0xdeadbeef))
+	u := newBigInt("0xd9a4b9a99984eadea545b42efe7cd1eb101d2e55b30d35eb7a79fc216c087c57")
+	v := newBigInt("0x93001ea19e5261a5b00428ec478d49d11df2e0b1ac5378e507e3c6c1359e0724")
+
+	b.Run("separate", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g.Multiply(u).Add(q.Multiply(v))
+		}
+	})
+
+	b.Run("straus", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g.DoubleScalarMultiply(u, q, v)
+		}
+	})
+}